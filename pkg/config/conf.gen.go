@@ -8,6 +8,15 @@ type WizWin struct {
 	WizClientId string `mapstructure:"wiz-client-id"`
 	WizClientSecret string `mapstructure:"wiz-client-secret"`
 	WizAuthEndpoint string `mapstructure:"wiz-auth-endpoint"`
+	WizFallbackRoleId string `mapstructure:"wiz-fallback-role-id"`
+	WizExpandProjectRoles bool `mapstructure:"wiz-expand-project-roles"`
+	WizIssueStatuses []string `mapstructure:"wiz-issue-statuses"`
+	WizIssueMinSeverity string `mapstructure:"wiz-issue-min-severity"`
+	WizIssueEntityTypes []string `mapstructure:"wiz-issue-entity-types"`
+	WizIssueTypes []string `mapstructure:"wiz-issue-types"`
+	WizIssueProjectIds []string `mapstructure:"wiz-issue-project-ids"`
+	WizIssueCreatedWithinDays int `mapstructure:"wiz-issue-created-within-days"`
+	WizIssueStateDir string `mapstructure:"wiz-issue-state-dir"`
 }
 
 func (c *WizWin) findFieldByTag(tagValue string) (any, bool) {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"github.com/conductorone/baton-sdk/pkg/field"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/issueenum"
 )
 
 var (
@@ -34,7 +35,83 @@ var (
 		field.WithPlaceholder("https://auth.app.wiz.io/oauth/token"),
 	)
 
-	ConfigurationFields = []field.SchemaField{wizAPIURL, wizClientID, wizClientSecret, wizAuthEndpoint}
+	// Provisioning configuration fields.
+	wizFallbackRoleID = field.StringField(
+		"wiz-fallback-role-id",
+		field.WithDisplayName("Fallback Role ID"),
+		field.WithDescription("Wiz role ID assigned to a user when their role is revoked, for example a read-only role. Role revocation is disabled when unset"),
+		field.WithPlaceholder("GLOBAL_READER"),
+	)
+	wizExpandProjectRoles = field.BoolField(
+		"wiz-expand-project-roles",
+		field.WithDisplayName("Expand Project Roles"),
+		field.WithDescription("Also sync a read-only entitlement per project-scoped role on each project (e.g. project:<id>:PROJECT_ADMIN), granted to the users holding that role on the project"),
+	)
+
+	// Security issue filter fields.
+	wizIssueStatuses = field.StringSliceField(
+		"wiz-issue-statuses",
+		field.WithDisplayName("Issue Statuses"),
+		field.WithDescription("Wiz issue statuses synced as security insights: OPEN, IN_PROGRESS, RESOLVED or REJECTED. Defaults to OPEN and IN_PROGRESS"),
+		field.WithStringSlice(func(r *field.StringSliceRuler) {
+			r.ItemRules(func(s *field.StringRuler) { s.In(issueenum.Statuses) })
+		}),
+	)
+	wizIssueMinSeverity = field.SelectField(
+		"wiz-issue-min-severity",
+		issueenum.Severities,
+		field.WithDisplayName("Minimum Issue Severity"),
+		field.WithDescription("Only sync issues of this severity or higher: INFORMATIONAL, LOW, MEDIUM, HIGH or CRITICAL. All severities are synced when unset"),
+	)
+	wizIssueEntityTypes = field.StringSliceField(
+		"wiz-issue-entity-types",
+		field.WithDisplayName("Issue Entity Types"),
+		field.WithDescription("Types of the entity an issue affects: USER_ACCOUNT, SERVICE_ACCOUNT, ACCESS_KEY, ROLE or GROUP. Defaults to USER_ACCOUNT and SERVICE_ACCOUNT"),
+		field.WithStringSlice(func(r *field.StringSliceRuler) {
+			r.ItemRules(func(s *field.StringRuler) { s.In(issueenum.EntityTypes) })
+		}),
+	)
+	wizIssueTypes = field.StringSliceField(
+		"wiz-issue-types",
+		field.WithDisplayName("Issue Types"),
+		field.WithDescription("Wiz issue types to sync: TOXIC_COMBINATION, THREAT_DETECTION or CLOUD_CONFIGURATION. All types are synced when unset"),
+		field.WithStringSlice(func(r *field.StringSliceRuler) {
+			r.ItemRules(func(s *field.StringRuler) { s.In(issueenum.Types) })
+		}),
+	)
+	wizIssueProjectIDs = field.StringSliceField(
+		"wiz-issue-project-ids",
+		field.WithDisplayName("Issue Project IDs"),
+		field.WithDescription("Only sync issues belonging to these Wiz project IDs. Issues from all projects are synced when unset"),
+	)
+	wizIssueCreatedWithinDays = field.IntField(
+		"wiz-issue-created-within-days",
+		field.WithDisplayName("Issue Age Limit (Days)"),
+		field.WithDescription("Only sync issues created within this many days. Issues of any age are synced when unset"),
+		field.WithInt(func(r *field.IntRuler) { r.Gte(0) }),
+	)
+	wizIssueStateDir = field.StringField(
+		"wiz-issue-state-dir",
+		field.WithDisplayName("Issue State Directory"),
+		field.WithDescription("Local directory where the connector keeps a watermark and a snapshot of each open issue between syncs. When set, syncs after the first only download issues updated since the previous sync "+
+			"and replay unchanged issues from the directory; issues resolved or rejected in between are dropped. The directory must persist between syncs"),
+	)
+
+	ConfigurationFields = []field.SchemaField{
+		wizAPIURL,
+		wizClientID,
+		wizClientSecret,
+		wizAuthEndpoint,
+		wizFallbackRoleID,
+		wizExpandProjectRoles,
+		wizIssueStatuses,
+		wizIssueMinSeverity,
+		wizIssueEntityTypes,
+		wizIssueTypes,
+		wizIssueProjectIDs,
+		wizIssueCreatedWithinDays,
+		wizIssueStateDir,
+	}
 
 	// FieldRelationships defines relationships between the ConfigurationFields that can be automatically validated.
 	FieldRelationships = []field.SchemaFieldRelationship{}
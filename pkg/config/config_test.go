@@ -23,6 +23,44 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid config - issue filters",
+			config: &WizWin{
+				WizApiUrl:                 "https://api.wiz.io/graphql",
+				WizClientId:               "test-client-id",
+				WizClientSecret:           "test-client-secret",
+				WizAuthEndpoint:           "https://auth.wiz.io/oauth/token",
+				WizIssueStatuses:          []string{"OPEN", "RESOLVED"},
+				WizIssueMinSeverity:       "HIGH",
+				WizIssueEntityTypes:       []string{"USER_ACCOUNT", "ACCESS_KEY"},
+				WizIssueTypes:             []string{"TOXIC_COMBINATION"},
+				WizIssueProjectIds:        []string{"project-1"},
+				WizIssueCreatedWithinDays: 30,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid config - unknown issue severity",
+			config: &WizWin{
+				WizApiUrl:           "https://api.wiz.io/graphql",
+				WizClientId:         "test-client-id",
+				WizClientSecret:     "test-client-secret",
+				WizAuthEndpoint:     "https://auth.wiz.io/oauth/token",
+				WizIssueMinSeverity: "SEVERE",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid config - unknown issue entity type",
+			config: &WizWin{
+				WizApiUrl:           "https://api.wiz.io/graphql",
+				WizClientId:         "test-client-id",
+				WizClientSecret:     "test-client-secret",
+				WizAuthEndpoint:     "https://auth.wiz.io/oauth/token",
+				WizIssueEntityTypes: []string{"VIRTUAL_MACHINE"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid config - missing required fields",
 			config: &WizWin{
@@ -0,0 +1,336 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	auditLogFeedID = "wiz_audit_log"
+	// defaultAuditLogLookback is how far back the feed starts when neither a cursor nor a start time is given.
+	defaultAuditLogLookback = 24 * time.Hour
+)
+
+// auditLogFeed streams Wiz audit log entries as usage, resource change and grant events.
+type auditLogFeed struct {
+	client wiz.Client
+
+	// mu serializes ListEvents calls, which share dir.
+	mu  sync.Mutex
+	dir *auditLogDirectory
+}
+
+// auditLogCursor is the feed position returned to the SDK, which persists it between runs.
+// While the entries recorded after Since are being paged through, After holds the Relay cursor and Last the
+// timestamp of the newest entry read so far. Once they are all read, Since moves up to Last and After is cleared.
+type auditLogCursor struct {
+	Since time.Time `json:"since"`
+	After string    `json:"after,omitempty"`
+	Last  time.Time `json:"last,omitempty"`
+}
+
+// auditLogParameters holds the mutation variables recorded in an audit log entry.
+type auditLogParameters struct {
+	Input struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Patch struct {
+			Role               *string   `json:"role"`
+			AssignedProjectIDs *[]string `json:"assignedProjectIds"`
+		} `json:"patch"`
+	} `json:"input"`
+}
+
+func (f *auditLogFeed) EventFeedMetadata(ctx context.Context) *v2.EventFeedMetadata {
+	return v2.EventFeedMetadata_builder{
+		Id: auditLogFeedID,
+		SupportedEventTypes: []v2.EventType{
+			v2.EventType_EVENT_TYPE_USAGE,
+			v2.EventType_EVENT_TYPE_RESOURCE_CHANGE,
+			v2.EventType_EVENT_TYPE_CREATE_GRANT,
+		},
+	}.Build()
+}
+
+// ListEvents returns the events for one page of audit log entries, oldest first.
+// Failed actions and actions that do not change access are skipped.
+func (f *auditLogFeed) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cursor := auditLogCursor{Since: time.Now().Add(-defaultAuditLogLookback)}
+	if earliestEvent != nil {
+		cursor.Since = earliestEvent.AsTime()
+	}
+	if pToken != nil && pToken.Cursor != "" {
+		if err := json.Unmarshal([]byte(pToken.Cursor), &cursor); err != nil {
+			return nil, nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid audit log cursor: %v", err)
+		}
+	}
+
+	var after *string
+	if cursor.After != "" {
+		after = &cursor.After
+	}
+	resp, err := f.client.ListAuditLogEntries(ctx, cursor.Since, after)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("wiz-connector: failed to list audit log entries: %w", err)
+	}
+
+	var events []*v2.Event
+	for _, entry := range resp.Nodes {
+		entryEvents, err := f.entryEvents(ctx, f.dir, &entry)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		events = append(events, entryEvents...)
+
+		if entry.Timestamp.After(cursor.Last) {
+			cursor.Last = entry.Timestamp
+		}
+	}
+
+	if resp.PageInfo.HasNextPage {
+		cursor.After = resp.PageInfo.EndCursor
+	} else {
+		if cursor.Last.After(cursor.Since) {
+			cursor.Since = cursor.Last
+		}
+		cursor = auditLogCursor{Since: cursor.Since}
+	}
+
+	nextCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("wiz-connector: failed to encode audit log cursor: %w", err)
+	}
+
+	return events, &pagination.StreamState{Cursor: string(nextCursor), HasMore: resp.PageInfo.HasNextPage}, nil, nil
+}
+
+// entryEvents maps an audit log entry to events. Entries whose target cannot be resolved, such as a user that
+// has since been deleted, are skipped.
+func (f *auditLogFeed) entryEvents(ctx context.Context, dir *auditLogDirectory, entry *wiz.AuditLogEntry) ([]*v2.Event, error) {
+	l := ctxzap.Extract(ctx)
+
+	// The actor's email is recorded on the entry, which lets the deletion of that user be resolved later on.
+	if entry.User != nil {
+		dir.learnUser(entry.User.ID, entry.User.Email)
+	}
+
+	if entry.Status != "SUCCESS" {
+		return nil, nil
+	}
+
+	var params auditLogParameters
+	if len(entry.ActionParameters) > 0 {
+		if err := json.Unmarshal(entry.ActionParameters, &params); err != nil {
+			l.Warn("wiz-connector: skipping audit log entry with unexpected parameters", zap.String("entry_id", entry.ID), zap.Error(err))
+			return nil, nil
+		}
+	}
+	input := params.Input
+
+	var events []*v2.Event
+	switch entry.Action {
+	case "Login":
+		if actor := auditLogActor(entry); actor != nil {
+			events = append(events, v2.Event_builder{
+				UsageEvent: v2.UsageEvent_builder{TargetResource: actor, ActorResource: actor}.Build(),
+			}.Build())
+		}
+
+	case "CreateUser":
+		events = append(events, resourceChangeEvent(userResourceType, input.Email))
+
+	case "DeleteUser", "UpdateUser":
+		email := input.Email
+		if email == "" {
+			var err error
+			if email, err = dir.userEmail(ctx, input.ID, entry.Timestamp); err != nil {
+				return nil, err
+			}
+		}
+		if email == "" {
+			if entry.Action == "DeleteUser" {
+				// Wiz only records the deleted user's ID, which can no longer be listed once the user is gone.
+				l.Warn("wiz-connector: skipping deletion of a user whose email is unknown", zap.String("entry_id", entry.ID), zap.String("user_id", input.ID))
+			} else {
+				l.Debug("wiz-connector: skipping audit log entry for unknown user", zap.String("entry_id", entry.ID), zap.String("user_id", input.ID))
+			}
+			return nil, nil
+		}
+		if entry.Action == "DeleteUser" || input.Patch.AssignedProjectIDs != nil {
+			// Project membership grants are emitted from the user, see users.go Grants().
+			events = append(events, resourceChangeEvent(userResourceType, email))
+		}
+		if input.Patch.Role != nil {
+			role := &v2.Resource{Id: &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: *input.Patch.Role}}
+			events = append(events, v2.Event_builder{
+				CreateGrantEvent: v2.CreateGrantEvent_builder{
+					Entitlement: ent.NewAssignmentEntitlement(role, "member"),
+					Principal:   &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: email}},
+				}.Build(),
+			}.Build())
+		}
+
+	case "UpdateProject":
+		events = append(events, resourceChangeEvent(projectResourceType, input.ID))
+
+	case "UpdateUserRole", "DeleteUserRole":
+		events = append(events, resourceChangeEvent(roleResourceType, input.ID))
+
+	case "CreateServiceAccount":
+		// The new account's ID is not recorded, so it is looked up by name.
+		serviceAccountID, err := dir.serviceAccountID(ctx, input.Name, entry.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		if serviceAccountID == "" {
+			l.Debug("wiz-connector: skipping audit log entry for unknown service account", zap.String("entry_id", entry.ID), zap.String("name", input.Name))
+			return nil, nil
+		}
+		events = append(events, resourceChangeEvent(serviceAccountResourceType, serviceAccountID))
+
+	case "DeleteServiceAccount":
+		events = append(events, resourceChangeEvent(serviceAccountResourceType, input.ID))
+	}
+
+	for i, event := range events {
+		eventID := entry.ID
+		if i > 0 {
+			eventID = fmt.Sprintf("%s-%d", entry.ID, i)
+		}
+		event.SetId(eventID)
+		event.SetOccurredAt(timestamppb.New(entry.Timestamp))
+	}
+
+	return events, nil
+}
+
+// auditLogActor returns the user or service account that performed the audited action.
+func auditLogActor(entry *wiz.AuditLogEntry) *v2.Resource {
+	switch {
+	case entry.User != nil && entry.User.Email != "":
+		// Users are keyed by email, see users.go
+		return &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: entry.User.Email}}
+	case entry.ServiceAccount != nil:
+		return &v2.Resource{
+			Id:          &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: entry.ServiceAccount.ID},
+			DisplayName: entry.ServiceAccount.Name,
+		}
+	default:
+		return nil
+	}
+}
+
+func resourceChangeEvent(resourceType *v2.ResourceType, id string) *v2.Event {
+	return v2.Event_builder{
+		ResourceChangeEvent: v2.ResourceChangeEvent_builder{
+			ResourceId: &v2.ResourceId{ResourceType: resourceType.Id, Resource: id},
+		}.Build(),
+	}.Build()
+}
+
+// auditLogDirectory resolves the Wiz identifiers recorded in audit log entries to synced resource IDs.
+// It lives as long as the feed, so a user listed before being deleted can still be resolved from the deletion entry.
+// Users and service accounts are listed again only when an unknown one appears in an entry recorded after the last
+// listing, i.e. one that may have been created since.
+type auditLogDirectory struct {
+	client wiz.Client
+
+	userEmails              map[string]string
+	usersListedAt           time.Time
+	serviceAccountIDs       map[string]string
+	serviceAccountsListedAt time.Time
+}
+
+// learnUser records the email of a user seen in an audit log entry.
+func (d *auditLogDirectory) learnUser(userID, email string) {
+	if userID != "" && email != "" {
+		d.userEmails[userID] = email
+	}
+}
+
+// userEmail returns the email of the user with the given Wiz ID, or "" if there is no such user.
+// at is the time of the entry the user was recorded in.
+func (d *auditLogDirectory) userEmail(ctx context.Context, userID string, at time.Time) (string, error) {
+	if email, ok := d.userEmails[userID]; ok || d.usersListedAt.After(at) {
+		return email, nil
+	}
+
+	listedAt := time.Now()
+	var cursor *string
+	for {
+		resp, err := d.client.ListUsers(ctx, cursor)
+		if err != nil {
+			return "", fmt.Errorf("wiz-connector: failed to list users: %w", err)
+		}
+		for _, user := range resp.Nodes {
+			d.learnUser(user.ID, user.Email)
+		}
+		if !resp.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &resp.PageInfo.EndCursor
+	}
+	d.usersListedAt = listedAt
+
+	return d.userEmails[userID], nil
+}
+
+// serviceAccountID returns the ID of the service account with the given name, or "" if there is no such account.
+// at is the time of the entry the account was recorded in.
+func (d *auditLogDirectory) serviceAccountID(ctx context.Context, name string, at time.Time) (string, error) {
+	if id, ok := d.serviceAccountIDs[name]; ok || d.serviceAccountsListedAt.After(at) {
+		return id, nil
+	}
+
+	listedAt := time.Now()
+	var cursor *string
+	for {
+		resp, err := d.client.ListServiceAccounts(ctx, cursor)
+		if err != nil {
+			return "", fmt.Errorf("wiz-connector: failed to list service accounts: %w", err)
+		}
+		for _, serviceAccount := range resp.Nodes {
+			d.serviceAccountIDs[serviceAccount.Name] = serviceAccount.ID
+		}
+		if !resp.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &resp.PageInfo.EndCursor
+	}
+	d.serviceAccountsListedAt = listedAt
+
+	return d.serviceAccountIDs[name], nil
+}
+
+func newAuditLogFeed(client wiz.Client) *auditLogFeed {
+	return &auditLogFeed{
+		client: client,
+		dir: &auditLogDirectory{
+			client:            client,
+			userEmails:        make(map[string]string),
+			serviceAccountIDs: make(map[string]string),
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAuditLogFeed(t *testing.T) {
+	ctx := context.Background()
+
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures(), wiztest.WithPageSize(3))
+	client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := newAuditLogFeed(client)
+	earliest := timestamppb.New(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+
+	var events []*v2.Event
+	cursor := ""
+	for {
+		page, state, _, err := feed.ListEvents(ctx, earliest, &pagination.StreamToken{Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, page...)
+		cursor = state.Cursor
+		if !state.HasMore {
+			break
+		}
+	}
+	assert.Equal(t, 3, server.Calls("ListAuditLogEntries"))
+
+	// The failed update and the saved query update do not change access.
+	byID := make(map[string]*v2.Event)
+	var ids []string
+	for _, event := range events {
+		byID[event.GetId()] = event
+		ids = append(ids, event.GetId())
+	}
+	assert.Equal(t, []string{"audit-1", "audit-2", "audit-3", "audit-3-1", "audit-5", "audit-6", "audit-8", "audit-9"}, ids)
+	// Users are listed once for the whole feed, not once per page.
+	assert.Equal(t, 1, server.Calls("ListUsers"))
+
+	login := byID["audit-1"].GetUsageEvent()
+	assert.Equal(t, "alice@example.com", login.GetActorResource().GetId().GetResource())
+	assert.Equal(t, time.Date(2025, 9, 1, 12, 30, 0, 0, time.UTC), byID["audit-1"].GetOccurredAt().AsTime())
+
+	assert.Equal(t, "carol@example.com", byID["audit-2"].GetResourceChangeEvent().GetResourceId().GetResource())
+	assert.Equal(t, "bob@example.com", byID["audit-3"].GetResourceChangeEvent().GetResourceId().GetResource())
+	roleGrant := byID["audit-3-1"].GetCreateGrantEvent()
+	assert.Equal(t, "role:PROJECT_MEMBER:member", roleGrant.GetEntitlement().GetId())
+	assert.Equal(t, "bob@example.com", roleGrant.GetPrincipal().GetId().GetResource())
+	assert.Equal(t, &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "project-1"}, byID["audit-5"].GetResourceChangeEvent().GetResourceId())
+	assert.Equal(t, &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "sa-2"}, byID["audit-6"].GetResourceChangeEvent().GetResourceId())
+	// dave was deleted, so only the login recorded before the deletion resolves his email.
+	assert.Equal(t, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "dave@example.com"}, byID["audit-9"].GetResourceChangeEvent().GetResourceId())
+
+	t.Run("cursor survives a restart", func(t *testing.T) {
+		restarted := newAuditLogFeed(client)
+		page, state, _, err := restarted.ListEvents(ctx, earliest, &pagination.StreamToken{Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, page)
+		assert.False(t, state.HasMore)
+		assert.Equal(t, cursor, state.Cursor)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, _, _, err := feed.ListEvents(ctx, earliest, &pagination.StreamToken{Cursor: "not-a-cursor"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestAuditLogFeedResolvesDeletedUsers(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.users = []wiz.User{{ID: "user-9", Email: "erin@example.com"}, {ID: "user-8", Email: "frank@example.com"}}
+	client.auditLogEntries = []wiz.AuditLogEntry{{
+		ID:               "audit-1",
+		Action:           "UpdateUser",
+		Status:           "SUCCESS",
+		Timestamp:        time.Now().Add(-time.Hour),
+		ActionParameters: []byte(`{"input": {"id": "user-8", "patch": {"assignedProjectIds": []}}}`),
+	}}
+	feed := newAuditLogFeed(client)
+
+	_, state, _, err := feed.ListEvents(ctx, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// erin is deleted after the feed listed the users, and is resolved from that listing.
+	client.users = client.users[1:]
+	client.auditLogEntries = []wiz.AuditLogEntry{{
+		ID:               "audit-2",
+		Action:           "DeleteUser",
+		Status:           "SUCCESS",
+		Timestamp:        time.Now(),
+		ActionParameters: []byte(`{"input": {"id": "user-9"}}`),
+	}}
+	events, _, _, err := feed.ListEvents(ctx, nil, &pagination.StreamToken{Cursor: state.Cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, events, 1) {
+		return
+	}
+	assert.Equal(t, "erin@example.com", events[0].GetResourceChangeEvent().GetResourceId().GetResource())
+	assert.Equal(t, 1, client.callCount("ListUsers"))
+}
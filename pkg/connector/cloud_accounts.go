@@ -0,0 +1,161 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+)
+
+// cloudAccountKinds names the kind of account each cloud provider connects to Wiz.
+var cloudAccountKinds = map[string]string{
+	"AWS":   "AWS account",
+	"Azure": "Azure subscription",
+	"GCP":   "GCP project",
+	"OCI":   "OCI tenancy",
+}
+
+type cloudAccountBuilder struct {
+	client wiz.Client
+}
+
+func (c *cloudAccountBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return cloudAccountResourceType
+}
+
+// List returns cloud accounts from Wiz as resource objects, one page at a time.
+// The IDs of the projects each account belongs to are stored in the profile for use in Grants().
+// Nothing is returned when the credentials lack read:cloud_accounts.
+func (c *cloudAccountBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	var cloudAccounts []*v2.Resource
+
+	// Get the page token from the sync attributes
+	var cursor *string
+	if attr.PageToken.Token != "" {
+		cursor = &attr.PageToken.Token
+	}
+
+	// Fetch one page of cloud accounts
+	resp, err := c.client.ListCloudAccounts(ctx, cursor)
+	if skipWithoutPermission(ctx, err, cloudAccountResourceType, "read:cloud_accounts") {
+		return nil, &resource.SyncOpResults{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to list cloud accounts: %w", err)
+	}
+
+	for _, cloudAccount := range resp.Nodes {
+		cloudAccountResource, err := newCloudAccountResource(&cloudAccount)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create cloud account resource: %w", err)
+		}
+
+		cloudAccounts = append(cloudAccounts, cloudAccountResource)
+	}
+
+	// Prepare the sync results with next page token if there are more pages
+	syncResults := &resource.SyncOpResults{}
+	if resp.PageInfo.HasNextPage {
+		syncResults.NextPageToken = resp.PageInfo.EndCursor
+	}
+
+	return cloudAccounts, syncResults, nil
+}
+
+// StaticEntitlements returns a static "access" entitlement for all cloud accounts.
+// It is granted to the projects containing the account and expands to the project's members, owners and champions.
+func (c *cloudAccountBuilder) StaticEntitlements(ctx context.Context, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	var entitlements []*v2.Entitlement
+	entitlements = append(
+		entitlements,
+		ent.NewPermissionEntitlement(
+			nil,
+			"access",
+			ent.WithDisplayName("Cloud Account Access"),
+			ent.WithDescription("Can see this cloud account's resources and issues in Wiz through a project containing it"),
+			ent.WithGrantableTo(projectResourceType),
+		),
+	)
+
+	return entitlements, nil, nil
+}
+
+// Entitlements is required by ResourceSyncerV2 but we use StaticEntitlements instead.
+// This should not be called due to the SkipEntitlements annotation on the resource type.
+func (c *cloudAccountBuilder) Entitlements(ctx context.Context, res *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// Grants returns an "access" grant to each project containing this cloud account.
+// The grants are expandable, so every user holding one of the project's entitlements is shown with access to the account.
+func (c *cloudAccountBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
+	var grants []*v2.Grant
+
+	// Extract the app trait to get the linked project IDs stored during List
+	appTrait, err := resource.GetAppTrait(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get app trait: %w", err)
+	}
+
+	for _, projectID := range profileStrings(appTrait.GetProfile().GetFields()["project_ids"]) {
+		projectResource, err := resource.NewResource("", projectResourceType, projectID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create project resource: %w", err)
+		}
+
+		grants = append(grants, grant.NewGrant(
+			res,
+			"access",
+			projectResource.Id,
+			grant.WithAnnotation(&v2.GrantExpandable{
+				EntitlementIds: []string{
+					ent.NewEntitlementID(projectResource, "member"),
+					ent.NewEntitlementID(projectResource, "owner"),
+					ent.NewEntitlementID(projectResource, "champion"),
+				},
+			}),
+		))
+	}
+
+	return grants, nil, nil
+}
+
+// newCloudAccountResource creates a cloud account resource keyed by its Wiz ID.
+// The provider-side account ID is set as the external ID so it can be matched with resources from cloud provider connectors.
+func newCloudAccountResource(cloudAccount *wiz.CloudAccount) (*v2.Resource, error) {
+	kind, ok := cloudAccountKinds[cloudAccount.CloudProvider]
+	if !ok {
+		kind = fmt.Sprintf("%s cloud account", cloudAccount.CloudProvider)
+	}
+
+	profile := map[string]interface{}{
+		"external_id":    cloudAccount.ExternalID,
+		"cloud_provider": cloudAccount.CloudProvider,
+		"status":         cloudAccount.Status,
+		"project_ids":    toInterfaceSlice(activeProjectIDs(cloudAccount.LinkedProjects)),
+	}
+
+	name := cloudAccount.Name
+	if name == "" {
+		name = cloudAccount.ExternalID
+	}
+
+	return resource.NewAppResource(
+		name,
+		cloudAccountResourceType,
+		cloudAccount.ID,
+		[]resource.AppTraitOption{
+			resource.WithAppProfile(profile),
+		},
+		resource.WithExternalID(&v2.ExternalId{Id: cloudAccount.ExternalID}),
+		resource.WithDescription(fmt.Sprintf("%s %s", kind, cloudAccount.ExternalID)),
+	)
+}
+
+func newCloudAccountBuilder(client wiz.Client) *cloudAccountBuilder {
+	return &cloudAccountBuilder{client: client}
+}
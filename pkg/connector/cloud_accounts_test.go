@@ -0,0 +1,81 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudAccountGrantsExpandToProjects(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.cloudAccounts = []wiz.CloudAccount{{
+		ID:            "ca-1",
+		Name:          "payments-prod",
+		ExternalID:    "123456789012",
+		CloudProvider: "AWS",
+		LinkedProjects: []wiz.ProjectRef{
+			{ID: "project-1", Name: "Payments"},
+			{ID: "project-2", Name: "Data Platform"},
+			// Archived projects are not synced, so they get no grant.
+			{ID: "project-3", Name: "Legacy", Archived: true},
+		},
+	}}
+	builder := newCloudAccountBuilder(client)
+
+	resources, _, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, resources, 1) {
+		return
+	}
+	res := resources[0]
+	assert.Equal(t, "123456789012", res.GetExternalId().GetId())
+	assert.Equal(t, "AWS account 123456789012", res.GetDescription())
+
+	grants, _, err := builder.Grants(ctx, res, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, grants, 2) {
+		return
+	}
+	assert.Equal(t, "cloud-account:ca-1:access", grants[0].GetEntitlement().GetId())
+	assert.Equal(t, "project-1", grants[0].GetPrincipal().GetId().GetResource())
+	assert.Equal(t, "project-2", grants[1].GetPrincipal().GetId().GetResource())
+
+	expandable := &v2.GrantExpandable{}
+	annos := annotations.Annotations(grants[0].GetAnnotations())
+	ok, err := annos.Pick(expandable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ok)
+	assert.Equal(t, []string{"project:project-1:member", "project:project-1:owner", "project:project-1:champion"}, expandable.GetEntitlementIds())
+}
+
+func TestCloudAccountsSkippedWithoutPermission(t *testing.T) {
+	ctx := context.Background()
+
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	server.InjectFault("ListCloudAccounts", wiztest.Fault{Errors: []wiztest.Error{{Message: "missing read:cloud_accounts", Code: "FORBIDDEN"}}})
+	client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, syncResults, err := newCloudAccountBuilder(client).List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, resources)
+	assert.Empty(t, syncResults.NextPageToken)
+}
@@ -4,26 +4,52 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/cli"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
 	cfg "github.com/conductorone/baton-wiz-win/pkg/config"
 	"github.com/conductorone/baton-wiz-win/pkg/wiz"
 )
 
 type Connector struct {
-	client wiz.Client
+	client         wiz.Client
+	fallbackRoleID string
+	// expandProjectRoles adds a per-project entitlement for each project-scoped role.
+	expandProjectRoles bool
+
+	issueFilter        wiz.IssueFilter
+	issueCreatedWithin time.Duration
+	// issueState keeps the issue watermark between syncs for incremental issue syncs; nil when disabled.
+	issueState sessions.SessionStore
+
+	// reportPollInterval is how often the run_report action checks on the report run, see global_actions.go.
+	reportPollInterval time.Duration
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (c *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncerV2 {
+	projects := newProjectBuilder(c.client, c.expandProjectRoles)
 	return []connectorbuilder.ResourceSyncerV2{
-		newUserBuilder(c.client),
-		newRoleBuilder(c.client),
-		newProjectBuilder(c.client),
-		newInsightBuilder(c.client),
+		newUserBuilder(c.client, c.expandProjectRoles),
+		newRoleBuilder(c.client, c.fallbackRoleID),
+		projects,
+		newProjectRoleBuilder(c.client, c.fallbackRoleID, &projects.locks),
+		newServiceAccountBuilder(c.client),
+		newPermissionBuilder(c.client),
+		newCloudAccountBuilder(c.client),
+		newSAMLGroupMappingBuilder(c.client, c.expandProjectRoles),
+		newInsightBuilder(c.client, c.issueFilter, c.issueCreatedWithin, c.issueState),
+	}
+}
+
+// EventFeeds returns the event feeds read by the connector: the Wiz audit log.
+func (c *Connector) EventFeeds(ctx context.Context) []connectorbuilder.EventFeed {
+	return []connectorbuilder.EventFeed{
+		newAuditLogFeed(c.client),
 	}
 }
 
@@ -36,11 +62,49 @@ func (c *Connector) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.R
 // Metadata returns metadata about the connector.
 func (c *Connector) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	return &v2.ConnectorMetadata{
-		DisplayName: "Wiz",
-		Description: "Wiz cloud security platform connector for syncing users, roles, projects, and security insights",
+		DisplayName:           "Wiz",
+		Description:           "Wiz cloud security platform connector for syncing users, roles, projects, and security insights",
+		AccountCreationSchema: accountCreationSchema,
 	}, nil
 }
 
+// accountCreationSchema describes the profile fields accepted by userBuilder.CreateAccount.
+var accountCreationSchema = &v2.ConnectorAccountCreationSchema{
+	FieldMap: map[string]*v2.ConnectorAccountCreationSchema_Field{
+		"email": {
+			DisplayName: "Email",
+			Required:    true,
+			Description: "Email address of the new Wiz user; an invite is sent to this address",
+			Placeholder: "user@example.com",
+			Field:       &v2.ConnectorAccountCreationSchema_Field_StringField{},
+			Order:       1,
+		},
+		"name": {
+			DisplayName: "Name",
+			Required:    false,
+			Description: "Display name of the new Wiz user; defaults to the email address",
+			Placeholder: "Jane Doe",
+			Field:       &v2.ConnectorAccountCreationSchema_Field_StringField{},
+			Order:       2,
+		},
+		"role_id": {
+			DisplayName: "Role ID",
+			Required:    true,
+			Description: "ID of the Wiz role initially assigned to the user",
+			Placeholder: "GLOBAL_READER",
+			Field:       &v2.ConnectorAccountCreationSchema_Field_StringField{},
+			Order:       3,
+		},
+		"project_ids": {
+			DisplayName: "Project IDs",
+			Required:    false,
+			Description: "IDs of the Wiz projects the user is assigned to; required for project-scoped roles",
+			Field:       &v2.ConnectorAccountCreationSchema_Field_StringListField{},
+			Order:       4,
+		},
+	},
+}
+
 // Validate is called to ensure that the connector is properly configured. It should exercise any API credentials
 // to be sure that they are valid.
 func (c *Connector) Validate(ctx context.Context) (annotations.Annotations, error) {
@@ -72,5 +136,37 @@ func New(ctx context.Context,
 		return nil, nil, fmt.Errorf("failed to create Wiz client: %w", err)
 	}
 
-	return &Connector{client: client}, nil, nil
+	issueFilter := wiz.IssueFilter{
+		Statuses:    connectorConfig.WizIssueStatuses,
+		MinSeverity: connectorConfig.WizIssueMinSeverity,
+		EntityTypes: connectorConfig.WizIssueEntityTypes,
+		Types:       connectorConfig.WizIssueTypes,
+		ProjectIDs:  connectorConfig.WizIssueProjectIds,
+	}
+	if len(issueFilter.Statuses) == 0 {
+		issueFilter.Statuses = wiz.DefaultIssueStatuses
+	}
+	if len(issueFilter.EntityTypes) == 0 {
+		issueFilter.EntityTypes = wiz.DefaultIssueEntityTypes
+	}
+	if err := issueFilter.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid issue filter configuration: %w", err)
+	}
+
+	var issueState sessions.SessionStore
+	if connectorConfig.WizIssueStateDir != "" {
+		if issueState, err = newDirSessionStore(connectorConfig.WizIssueStateDir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &Connector{
+		client:             client,
+		fallbackRoleID:     connectorConfig.WizFallbackRoleId,
+		expandProjectRoles: connectorConfig.WizExpandProjectRoles,
+		issueFilter:        issueFilter,
+		issueCreatedWithin: time.Duration(connectorConfig.WizIssueCreatedWithinDays) * 24 * time.Hour,
+		issueState:         issueState,
+		reportPollInterval: defaultReportPollInterval,
+	}, nil, nil
 }
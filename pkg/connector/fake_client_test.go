@@ -0,0 +1,562 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClient is an in-memory wiz.Client that records how many times each method is called.
+type fakeClient struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	pageSize int
+
+	users    []wiz.User
+	projects []wiz.Project
+	roles    []wiz.UserRole
+	issues   []wiz.Issue
+
+	serviceAccounts []wiz.ServiceAccount
+	cloudAccounts   []wiz.CloudAccount
+	auditLogEntries []wiz.AuditLogEntry
+	reports         []wiz.Report
+
+	samlIdentityProviders []wiz.SAMLIdentityProvider
+
+	// issueFilters holds the filter passed to each ListIssues call.
+	issueFilters []wiz.IssueFilter
+	// issueReasons holds the resolution reason passed to each UpdateIssue call.
+	issueReasons []*string
+	// projectInputs holds the input passed to each CreateProject call.
+	projectInputs []wiz.CreateProjectInput
+}
+
+var _ wiz.Client = (*fakeClient)(nil)
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		calls:    make(map[string]int),
+		pageSize: 100,
+	}
+}
+
+func (f *fakeClient) record(method string) {
+	f.calls[method]++
+}
+
+// callCount returns the number of times method was called.
+func (f *fakeClient) callCount(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// page returns the page of items starting at the offset encoded in cursor.
+func page[T any](items []T, cursor *string, size int) ([]T, wiz.PageInfo) {
+	start := 0
+	if cursor != nil && *cursor != "" {
+		start, _ = strconv.Atoi(*cursor)
+	}
+	end := min(start+size, len(items))
+	if start > end {
+		start = end
+	}
+
+	info := wiz.PageInfo{}
+	if end < len(items) {
+		info.HasNextPage = true
+		info.EndCursor = strconv.Itoa(end)
+	}
+	return items[start:end], info
+}
+
+func (f *fakeClient) ListUsers(ctx context.Context, cursor *string) (*wiz.UserConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListUsers")
+
+	nodes, info := page(f.users, cursor, f.pageSize)
+	return &wiz.UserConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) ListProjects(ctx context.Context, cursor *string) (*wiz.ProjectConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListProjects")
+
+	nodes, info := page(f.projects, cursor, f.pageSize)
+	return &wiz.ProjectConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) ListUserRoles(ctx context.Context, cursor *string) (*wiz.UserRoleConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListUserRoles")
+
+	return &wiz.UserRoleConnection{Nodes: f.roles}, nil
+}
+
+func (f *fakeClient) GetRole(ctx context.Context, roleID string) (*wiz.UserRole, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetRole")
+
+	for _, role := range f.roles {
+		if role.ID == roleID {
+			return &role, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "role %s not found", roleID)
+}
+
+func (f *fakeClient) ListIssues(ctx context.Context, filter wiz.IssueFilter, cursor *string) (*wiz.IssueConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListIssues")
+	f.issueFilters = append(f.issueFilters, filter)
+
+	issues := f.issues
+	if filter.UpdatedAfter != nil {
+		issues = nil
+		for _, issue := range f.issues {
+			if issue.UpdatedAt.After(*filter.UpdatedAfter) {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	nodes, info := page(issues, cursor, f.pageSize)
+	return &wiz.IssueConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) UpdateIssue(ctx context.Context, issueID string, patch wiz.UpdateIssuePatch) (*wiz.Issue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("UpdateIssue")
+
+	for i := range f.issues {
+		if f.issues[i].ID != issueID {
+			continue
+		}
+		if patch.Status != nil {
+			f.issues[i].Status = *patch.Status
+		}
+		if patch.Note != nil {
+			f.issues[i].Notes = append(f.issues[i].Notes, wiz.IssueNote{Text: *patch.Note})
+		}
+		if patch.DueAt != nil {
+			f.issues[i].DueAt = patch.DueAt
+		}
+		f.issueReasons = append(f.issueReasons, patch.ResolutionReason)
+		issue := f.issues[i]
+		return &issue, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "issue %s not found", issueID)
+}
+
+func (f *fakeClient) CreateIssueNote(ctx context.Context, issueID, text string) (*wiz.IssueNote, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateIssueNote")
+
+	for i := range f.issues {
+		if f.issues[i].ID == issueID {
+			note := wiz.IssueNote{CreatedAt: time.Now(), Text: text}
+			f.issues[i].Notes = append(f.issues[i].Notes, note)
+			return &note, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "issue %s not found", issueID)
+}
+
+func (f *fakeClient) GetUserByEmail(ctx context.Context, email string) (*wiz.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetUserByEmail")
+
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "user %s not found", email)
+}
+
+func (f *fakeClient) UpdateUser(ctx context.Context, userID string, patch wiz.UpdateUserPatch) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("UpdateUser")
+
+	for i := range f.users {
+		if f.users[i].ID != userID {
+			continue
+		}
+		if patch.Role != nil {
+			f.users[i].EffectiveRole = wiz.UserRoleRef{ID: *patch.Role}
+		}
+		if patch.AssignedProjectIDs != nil {
+			refs := make([]wiz.ProjectRef, 0, len(*patch.AssignedProjectIDs))
+			for _, id := range *patch.AssignedProjectIDs {
+				refs = append(refs, wiz.ProjectRef{ID: id})
+			}
+			f.users[i].AssignedProjects = refs
+			f.users[i].EffectiveAssignedProjects = refs
+		}
+		return nil
+	}
+	return status.Errorf(codes.NotFound, "user %s not found", userID)
+}
+
+func (f *fakeClient) GetProject(ctx context.Context, projectID string) (*wiz.Project, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetProject")
+
+	for _, project := range f.projects {
+		if project.ID == projectID {
+			return &project, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "project %s not found", projectID)
+}
+
+func (f *fakeClient) UpdateProject(ctx context.Context, projectID string, patch wiz.UpdateProjectPatch) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("UpdateProject")
+
+	for i := range f.projects {
+		if f.projects[i].ID != projectID {
+			continue
+		}
+		if patch.ProjectOwners != nil {
+			f.projects[i].ProjectOwners = nil
+			for _, id := range *patch.ProjectOwners {
+				f.projects[i].ProjectOwners = append(f.projects[i].ProjectOwners, wiz.ProjectOwner{ID: id, Email: f.emailForUserID(id)})
+			}
+		}
+		if patch.SecurityChampions != nil {
+			f.projects[i].SecurityChampions = nil
+			for _, id := range *patch.SecurityChampions {
+				f.projects[i].SecurityChampions = append(f.projects[i].SecurityChampions, wiz.SecurityChampion{ID: id, Email: f.emailForUserID(id)})
+			}
+		}
+		if patch.Archived != nil {
+			f.projects[i].Archived = *patch.Archived
+		}
+		return nil
+	}
+	return status.Errorf(codes.NotFound, "project %s not found", projectID)
+}
+
+func (f *fakeClient) CreateProject(ctx context.Context, input wiz.CreateProjectInput) (*wiz.Project, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateProject")
+	f.projectInputs = append(f.projectInputs, input)
+
+	project := wiz.Project{
+		ID:           "project-" + strconv.Itoa(len(f.projects)+1),
+		Name:         input.Name,
+		Description:  input.Description,
+		BusinessUnit: input.BusinessUnit,
+	}
+	for _, id := range input.ProjectOwners {
+		project.ProjectOwners = append(project.ProjectOwners, wiz.ProjectOwner{ID: id, Email: f.emailForUserID(id)})
+	}
+	for _, id := range input.SecurityChampions {
+		project.SecurityChampions = append(project.SecurityChampions, wiz.SecurityChampion{ID: id, Email: f.emailForUserID(id)})
+	}
+	f.projects = append(f.projects, project)
+	return &project, nil
+}
+
+func (f *fakeClient) CreateUser(ctx context.Context, input wiz.CreateUserInput) (*wiz.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateUser")
+
+	user := wiz.User{
+		ID:            "user-" + strconv.Itoa(len(f.users)+1),
+		Name:          input.Name,
+		Email:         input.Email,
+		EffectiveRole: wiz.UserRoleRef{ID: input.Role},
+	}
+	for _, id := range input.AssignedProjectIDs {
+		user.AssignedProjects = append(user.AssignedProjects, wiz.ProjectRef{ID: id})
+	}
+	user.EffectiveAssignedProjects = user.AssignedProjects
+	f.users = append(f.users, user)
+	return &user, nil
+}
+
+func (f *fakeClient) DeleteUser(ctx context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("DeleteUser")
+
+	for i := range f.users {
+		if f.users[i].ID == userID {
+			f.users = append(f.users[:i], f.users[i+1:]...)
+			return nil
+		}
+	}
+	return status.Errorf(codes.NotFound, "user %s not found", userID)
+}
+
+func (f *fakeClient) ListServiceAccounts(ctx context.Context, cursor *string) (*wiz.ServiceAccountConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListServiceAccounts")
+
+	nodes, info := page(f.serviceAccounts, cursor, f.pageSize)
+	return &wiz.ServiceAccountConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) RotateServiceAccountSecret(ctx context.Context, serviceAccountID string) (*wiz.ServiceAccountCredentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("RotateServiceAccountSecret")
+
+	for _, serviceAccount := range f.serviceAccounts {
+		if serviceAccount.ID == serviceAccountID {
+			return &wiz.ServiceAccountCredentials{
+				ID:           serviceAccount.ID,
+				ClientID:     serviceAccount.ClientID,
+				ClientSecret: "rotated-" + strconv.Itoa(f.calls["RotateServiceAccountSecret"]),
+			}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "service account %s not found", serviceAccountID)
+}
+
+func (f *fakeClient) ListCloudAccounts(ctx context.Context, cursor *string) (*wiz.CloudAccountConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListCloudAccounts")
+
+	nodes, info := page(f.cloudAccounts, cursor, f.pageSize)
+	return &wiz.CloudAccountConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) ListSAMLIdentityProviders(ctx context.Context, cursor *string) (*wiz.SAMLIdentityProviderConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListSAMLIdentityProviders")
+
+	nodes, info := page(f.samlIdentityProviders, cursor, f.pageSize)
+	return &wiz.SAMLIdentityProviderConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) GetCloudAccount(ctx context.Context, cloudAccountID string) (*wiz.CloudAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetCloudAccount")
+
+	for _, account := range f.cloudAccounts {
+		if account.ID == cloudAccountID {
+			return &account, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "cloud account %s not found", cloudAccountID)
+}
+
+func (f *fakeClient) RequestConnectorScan(ctx context.Context, connectorID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("RequestConnectorScan")
+	return nil
+}
+
+// GetReport returns the report as is: runs started by RerunReport never finish unless a test changes them.
+func (f *fakeClient) GetReport(ctx context.Context, reportID string) (*wiz.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetReport")
+
+	for _, report := range f.reports {
+		if report.ID == reportID {
+			return &report, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "report %s not found", reportID)
+}
+
+func (f *fakeClient) RerunReport(ctx context.Context, reportID string) (*wiz.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("RerunReport")
+
+	for i := range f.reports {
+		if f.reports[i].ID == reportID {
+			now := time.Now()
+			f.reports[i].LastRun = &wiz.ReportRun{Status: "IN_PROGRESS", RunAt: &now}
+			report := f.reports[i]
+			return &report, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "report %s not found", reportID)
+}
+
+func (f *fakeClient) ListAuditLogEntries(ctx context.Context, since time.Time, cursor *string) (*wiz.AuditLogEntryConnection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("ListAuditLogEntries")
+
+	var entries []wiz.AuditLogEntry
+	for _, entry := range f.auditLogEntries {
+		if entry.Timestamp.After(since) {
+			entries = append(entries, entry)
+		}
+	}
+	nodes, info := page(entries, cursor, f.pageSize)
+	return &wiz.AuditLogEntryConnection{Nodes: nodes, PageInfo: info}, nil
+}
+
+func (f *fakeClient) emailForUserID(id string) string {
+	for _, user := range f.users {
+		if user.ID == id {
+			return user.Email
+		}
+	}
+	return ""
+}
+
+func (f *fakeClient) CreateUserRole(ctx context.Context, input wiz.CreateUserRoleInput) (*wiz.UserRole, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateUserRole")
+
+	role := wiz.UserRole{
+		ID:              "role-" + strconv.Itoa(len(f.roles)+1),
+		Name:            input.Name,
+		Description:     input.Description,
+		Scopes:          input.Scopes,
+		IsProjectScoped: input.IsProjectScoped,
+	}
+	f.roles = append(f.roles, role)
+	return &role, nil
+}
+
+func (f *fakeClient) UpdateUserRole(ctx context.Context, roleID string, patch wiz.UpdateUserRolePatch) (*wiz.UserRole, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("UpdateUserRole")
+
+	for i := range f.roles {
+		if f.roles[i].ID != roleID {
+			continue
+		}
+		if patch.Name != nil {
+			f.roles[i].Name = *patch.Name
+		}
+		if patch.Description != nil {
+			f.roles[i].Description = *patch.Description
+		}
+		if patch.Scopes != nil {
+			f.roles[i].Scopes = *patch.Scopes
+		}
+		role := f.roles[i]
+		return &role, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "role %s not found", roleID)
+}
+
+func (f *fakeClient) DeleteUserRole(ctx context.Context, roleID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("DeleteUserRole")
+
+	for i := range f.roles {
+		if f.roles[i].ID == roleID {
+			f.roles = append(f.roles[:i], f.roles[i+1:]...)
+			return nil
+		}
+	}
+	return status.Errorf(codes.NotFound, "role %s not found", roleID)
+}
+
+// memorySessionStore is an in-memory sessions.SessionStore. GetAll returns everything in a single page.
+type memorySessionStore struct {
+	values map[string][]byte
+}
+
+var _ sessions.SessionStore = (*memorySessionStore)(nil)
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{values: make(map[string][]byte)}
+}
+
+func (m *memorySessionStore) bag(ctx context.Context, opt []sessions.SessionStoreOption) *sessions.SessionStoreBag {
+	bag := &sessions.SessionStoreBag{}
+	for _, o := range opt {
+		_ = o(ctx, bag)
+	}
+	return bag
+}
+
+func (m *memorySessionStore) key(bag *sessions.SessionStoreBag, key string) string {
+	return bag.SyncID + "|" + bag.Prefix + key
+}
+
+func (m *memorySessionStore) Get(ctx context.Context, key string, opt ...sessions.SessionStoreOption) ([]byte, bool, error) {
+	value, ok := m.values[m.key(m.bag(ctx, opt), key)]
+	return value, ok, nil
+}
+
+func (m *memorySessionStore) GetMany(ctx context.Context, keys []string, opt ...sessions.SessionStoreOption) (map[string][]byte, []string, error) {
+	bag := m.bag(ctx, opt)
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		if value, ok := m.values[m.key(bag, key)]; ok {
+			values[key] = value
+		}
+	}
+	return values, nil, nil
+}
+
+func (m *memorySessionStore) Set(ctx context.Context, key string, value []byte, opt ...sessions.SessionStoreOption) error {
+	m.values[m.key(m.bag(ctx, opt), key)] = value
+	return nil
+}
+
+func (m *memorySessionStore) SetMany(ctx context.Context, values map[string][]byte, opt ...sessions.SessionStoreOption) error {
+	bag := m.bag(ctx, opt)
+	for key, value := range values {
+		m.values[m.key(bag, key)] = value
+	}
+	return nil
+}
+
+func (m *memorySessionStore) Delete(ctx context.Context, key string, opt ...sessions.SessionStoreOption) error {
+	delete(m.values, m.key(m.bag(ctx, opt), key))
+	return nil
+}
+
+func (m *memorySessionStore) Clear(ctx context.Context, opt ...sessions.SessionStoreOption) error {
+	prefix := m.key(m.bag(ctx, opt), "")
+	for key := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.values, key)
+		}
+	}
+	return nil
+}
+
+func (m *memorySessionStore) GetAll(ctx context.Context, pageToken string, opt ...sessions.SessionStoreOption) (map[string][]byte, string, error) {
+	prefix := m.key(m.bag(ctx, opt), "")
+	values := make(map[string][]byte)
+	for key, value := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			values[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	return values, "", nil
+}
@@ -0,0 +1,243 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// defaultReportPollInterval is how often run_report checks whether the report run has finished.
+	defaultReportPollInterval = 5 * time.Second
+	// defaultReportWait and maxReportWait bound how long run_report waits for the run to finish, in seconds.
+	defaultReportWait = 60
+	maxReportWait     = 300
+)
+
+// GlobalActions registers the actions used by remediation playbooks: rescanning a cloud account after access was
+// revoked, checking on that scan, and running a report.
+func (c *Connector) GlobalActions(ctx context.Context, registry actions.ActionRegistry) error {
+	if err := registry.Register(ctx, rescanCloudAccountActionSchema, c.rescanCloudAccount); err != nil {
+		return err
+	}
+	if err := registry.Register(ctx, getScanStatusActionSchema, c.getScanStatus); err != nil {
+		return err
+	}
+	return registry.Register(ctx, runReportActionSchema, c.runReport)
+}
+
+// cloudAccountActionArg is the cloud account argument of the scan actions.
+var cloudAccountActionArg = config.Field_builder{
+	Name:            "cloud_account",
+	DisplayName:     "Cloud Account",
+	Description:     "The Wiz cloud account to scan",
+	IsRequired:      true,
+	ResourceIdField: &config.ResourceIdField{},
+}.Build()
+
+var rescanCloudAccountActionSchema = v2.BatonActionSchema_builder{
+	Name:        "rescan_cloud_account",
+	DisplayName: "Rescan Cloud Account",
+	Description: "Start an on-demand Wiz scan of a cloud account, e.g. to confirm an issue disappears after access was revoked. " +
+		"The scan runs asynchronously; use get_scan_status to follow it",
+	Arguments: []*config.Field{cloudAccountActionArg},
+	ReturnTypes: []*config.Field{
+		config.Field_builder{Name: "success", BoolField: &config.BoolField{}}.Build(),
+		config.Field_builder{Name: "cloud_account_id", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "connector_ids", StringSliceField: &config.StringSliceField{}}.Build(),
+	},
+	ActionType: []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+var getScanStatusActionSchema = v2.BatonActionSchema_builder{
+	Name:        "get_scan_status",
+	DisplayName: "Get Scan Status",
+	Description: "Get the status of a cloud account, the status of each Wiz connector scanning it by connector ID, and when it was last scanned",
+	Arguments:   []*config.Field{cloudAccountActionArg},
+	ReturnTypes: []*config.Field{
+		config.Field_builder{Name: "success", BoolField: &config.BoolField{}}.Build(),
+		config.Field_builder{Name: "cloud_account_id", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "status", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "last_scanned_at", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "connector_statuses", StringMapField: &config.StringMapField{}}.Build(),
+	},
+	ActionType: []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+var runReportActionSchema = v2.BatonActionSchema_builder{
+	Name:        "run_report",
+	DisplayName: "Run Report",
+	Description: "Run a saved Wiz report and wait for it to finish",
+	Arguments: []*config.Field{
+		config.Field_builder{
+			Name:        "report_id",
+			DisplayName: "Report ID",
+			Description: "ID of the Wiz report to run",
+			IsRequired:  true,
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "wait_seconds",
+			DisplayName: "Wait",
+			Description: fmt.Sprintf("Seconds to wait for the run to finish, at most %d. 0 returns as soon as the run started", maxReportWait),
+			IntField:    config.IntField_builder{DefaultValue: defaultReportWait}.Build(),
+		}.Build(),
+	},
+	ReturnTypes: []*config.Field{
+		config.Field_builder{Name: "success", BoolField: &config.BoolField{}}.Build(),
+		config.Field_builder{Name: "report_id", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "status", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "completed", BoolField: &config.BoolField{}}.Build(),
+		config.Field_builder{Name: "run_at", StringField: &config.StringField{}}.Build(),
+		config.Field_builder{Name: "url", StringField: &config.StringField{}}.Build(),
+	},
+	ActionType: []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+// rescanCloudAccount handles the rescan_cloud_account action by requesting a scan from every enabled connector
+// of the cloud account.
+func (c *Connector) rescanCloudAccount(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	account, err := c.cloudAccountArg(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var connectorIDs []string
+	for _, connector := range account.SourceConnectors {
+		if connector.Status == "DISABLED" {
+			continue
+		}
+		if err := c.client.RequestConnectorScan(ctx, connector.ID); err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to request a scan from connector %s: %w", connector.ID, err)
+		}
+		l.Info("wiz-connector: requested cloud account scan", zap.String("cloud_account_id", account.ID), zap.String("connector_id", connector.ID))
+		connectorIDs = append(connectorIDs, connector.ID)
+	}
+	if len(connectorIDs) == 0 {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "wiz-connector: cloud account %s has no enabled connector to scan it", account.ID)
+	}
+
+	return actions.NewReturnValues(true,
+		actions.NewStringReturnField("cloud_account_id", account.ID),
+		actions.NewStringListReturnField("connector_ids", connectorIDs),
+	), nil, nil
+}
+
+// getScanStatus handles the get_scan_status action.
+func (c *Connector) getScanStatus(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	account, err := c.cloudAccountArg(ctx, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lastScannedAt time.Time
+	connectorStatuses := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(account.SourceConnectors))}
+	for _, connector := range account.SourceConnectors {
+		connectorStatuses.Fields[connector.ID] = structpb.NewStringValue(connector.Status)
+		if connector.LastActivity != nil && connector.LastActivity.After(lastScannedAt) {
+			lastScannedAt = *connector.LastActivity
+		}
+	}
+
+	return actions.NewReturnValues(true,
+		actions.NewStringReturnField("cloud_account_id", account.ID),
+		actions.NewStringReturnField("status", account.Status),
+		actions.NewStringReturnField("last_scanned_at", formatOptionalTime(lastScannedAt)),
+		actions.NewReturnField("connector_statuses", structpb.NewStructValue(connectorStatuses)),
+	), nil, nil
+}
+
+// cloudAccountArg returns the cloud account in the cloud_account argument, with its connectors.
+func (c *Connector) cloudAccountArg(ctx context.Context, args *structpb.Struct) (*wiz.CloudAccount, error) {
+	resourceID, err := actions.RequireResourceIDArg(args, "cloud_account")
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "wiz-connector: %s", err)
+	}
+	if resourceID.GetResourceType() != cloudAccountResourceType.Id {
+		return nil, status.Errorf(codes.InvalidArgument, "wiz-connector: expected a %s resource, got %s", cloudAccountResourceType.Id, resourceID.GetResourceType())
+	}
+
+	account, err := c.client.GetCloudAccount(ctx, resourceID.GetResource())
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to get cloud account %s: %w", resourceID.GetResource(), err)
+	}
+	return account, nil
+}
+
+// runReport handles the run_report action. It starts a new run of the report and polls it until it finishes or the
+// requested wait elapses; a run still in progress at that point is returned with completed set to false.
+func (c *Connector) runReport(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	reportID, err := actions.RequireStringArg(args, "report_id")
+	if err != nil || reportID == "" {
+		return nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: a report ID is required")
+	}
+	wait := int64(defaultReportWait)
+	if value, ok := actions.GetIntArg(args, "wait_seconds"); ok {
+		wait = value
+	}
+	if wait < 0 || wait > maxReportWait {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: wait_seconds must be between 0 and %d", maxReportWait)
+	}
+
+	report, err := c.client.RerunReport(ctx, reportID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to run report %s: %w", reportID, err)
+	}
+
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	for !reportRunFinished(report) && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(min(c.reportPollInterval, time.Until(deadline))):
+		}
+
+		if report, err = c.client.GetReport(ctx, reportID); err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to get report %s: %w", reportID, err)
+		}
+	}
+
+	run := report.LastRun
+	if run == nil {
+		run = &wiz.ReportRun{}
+	}
+	var runAt time.Time
+	if run.RunAt != nil {
+		runAt = *run.RunAt
+	}
+
+	return actions.NewReturnValues(run.Status != "FAILED",
+		actions.NewStringReturnField("report_id", report.ID),
+		actions.NewStringReturnField("status", run.Status),
+		actions.NewBoolReturnField("completed", run.Status == "COMPLETED"),
+		actions.NewStringReturnField("run_at", formatOptionalTime(runAt)),
+		actions.NewStringReturnField("url", run.URL),
+	), nil, nil
+}
+
+// reportRunFinished reports whether the last run of report is over, successfully or not.
+func reportRunFinished(report *wiz.Report) bool {
+	return report.LastRun != nil && slices.Contains(wiz.ReportRunTerminalStatuses, report.LastRun.Status)
+}
+
+// formatOptionalTime formats t as RFC 3339, or returns "" for the zero time.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
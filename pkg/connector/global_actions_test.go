@@ -0,0 +1,120 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestScanActions(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Connector{client: client, reportPollInterval: time.Millisecond}
+
+	cloudAccountArgs := func(t *testing.T, cloudAccountID string) *structpb.Struct {
+		t.Helper()
+		args, err := structpb.NewStruct(map[string]interface{}{
+			"cloud_account": map[string]interface{}{"resource_type_id": cloudAccountResourceType.Id, "resource_id": cloudAccountID},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args
+	}
+
+	result, _, err := c.rescanCloudAccount(ctx, cloudAccountArgs(t, "cloud-account-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result.GetFields()["success"].GetBoolValue())
+	assert.Equal(t, "connector-aws", result.GetFields()["connector_ids"].GetListValue().GetValues()[0].GetStringValue())
+	assert.Equal(t, 1, server.Calls("RequestConnectorScan"))
+
+	// The rescan is reflected in the connector's last activity.
+	result, _, err = c.getScanStatus(ctx, cloudAccountArgs(t, "cloud-account-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "CONNECTED", result.GetFields()["status"].GetStringValue())
+	assert.Equal(t, "CONNECTED", result.GetFields()["connector_statuses"].GetStructValue().GetFields()["connector-aws"].GetStringValue())
+	lastScannedAt, err := time.Parse(time.RFC3339, result.GetFields()["last_scanned_at"].GetStringValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.WithinDuration(t, time.Now(), lastScannedAt, time.Minute)
+
+	// cloud-account-3 has no connector to scan it.
+	_, _, err = c.rescanCloudAccount(ctx, cloudAccountArgs(t, "cloud-account-3"))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, _, err = c.getScanStatus(ctx, cloudAccountArgs(t, "cloud-account-404"))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRunReport(t *testing.T) {
+	ctx := context.Background()
+
+	reportArgs := func(t *testing.T, fields map[string]interface{}) *structpb.Struct {
+		t.Helper()
+		fields["report_id"] = "report-1"
+		args, err := structpb.NewStruct(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args
+	}
+
+	t.Run("waits for the run to complete", func(t *testing.T) {
+		server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+		client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &Connector{client: client, reportPollInterval: time.Millisecond}
+
+		result, _, err := c.runReport(ctx, reportArgs(t, map[string]interface{}{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result.GetFields()["completed"].GetBoolValue())
+		assert.Equal(t, "COMPLETED", result.GetFields()["status"].GetStringValue())
+		assert.NotEmpty(t, result.GetFields()["url"].GetStringValue())
+		assert.Equal(t, 1, server.Calls("GetReport"))
+	})
+
+	t.Run("stops waiting after wait_seconds", func(t *testing.T) {
+		client := newFakeClient()
+		client.reports = []wiz.Report{{ID: "report-1"}}
+		c := &Connector{client: client, reportPollInterval: 100 * time.Millisecond}
+
+		started := time.Now()
+		result, _, err := c.runReport(ctx, reportArgs(t, map[string]interface{}{"wait_seconds": 1}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.WithinDuration(t, started.Add(time.Second), time.Now(), 500*time.Millisecond)
+		assert.True(t, result.GetFields()["success"].GetBoolValue())
+		assert.False(t, result.GetFields()["completed"].GetBoolValue())
+		assert.Equal(t, "IN_PROGRESS", result.GetFields()["status"].GetStringValue())
+	})
+
+	t.Run("wait is bounded", func(t *testing.T) {
+		client := newFakeClient()
+		c := &Connector{client: client, reportPollInterval: time.Millisecond}
+
+		_, _, err := c.runReport(ctx, reportArgs(t, map[string]interface{}{"wait_seconds": 3600}))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 0, client.callCount("RerunReport"))
+	})
+}
@@ -2,15 +2,37 @@ package connector
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/session"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
 	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type insightBuilder struct {
-	client wiz.Client
+	client        wiz.Client
+	filter        wiz.IssueFilter
+	createdWithin time.Duration
+	// state keeps the issue watermark and snapshots between syncs. When set, only the issues updated since the
+	// previous sync are downloaded, see listIncremental.
+	state sessions.SessionStore
 }
 
 func (i *insightBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -19,76 +41,517 @@ func (i *insightBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 
 // List returns security insights from Wiz as resource objects with SecurityInsightTrait.
 // This properly handles pagination by returning one page at a time.
+// With a state store, only issues updated since the previous sync are downloaded, see listIncremental.
 func (i *insightBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	if i.state != nil {
+		return i.listIncremental(ctx, attr)
+	}
+
 	var insights []*v2.Resource
 
-	// Get the page token from the sync attributes
-	var cursor *string
+	// Get the page token from the sync attributes; the created-after cutoff is resolved on the first page
+	token := issuePageToken{CreatedAfter: i.createdAfter()}
 	if attr.PageToken.Token != "" {
-		cursor = &attr.PageToken.Token
+		if err := json.Unmarshal([]byte(attr.PageToken.Token), &token); err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid issue page token: %v", err)
+		}
 	}
 
 	// Fetch one page of issues
-	resp, err := i.client.ListIssues(ctx, cursor)
+	resp, err := i.client.ListIssues(ctx, i.issueFilter(token.CreatedAfter), relayCursor(token.Cursor))
 	if err != nil {
 		return nil, nil, fmt.Errorf("wiz-connector: failed to list issues: %w", err)
 	}
 
 	for _, issue := range resp.Nodes {
 		// Skip issues without external IDs or issue IDs
-		// Note: Server-side filtering already ensures we only get USER_ACCOUNT and SERVICE_ACCOUNT issues
+		// Note: Server-side filtering already limits issues to the configured statuses, severities and entity types
 		if issue.EntitySnapshot.ExternalID == "" || issue.ID == "" {
 			continue
 		}
 
-		// Create a unique resource ID combining issue ID and external resource ID
-		resourceID := fmt.Sprintf("%s:%s", issue.ID, issue.EntitySnapshot.ExternalID)
-
-		// Create the insight value with severity and rule name
-		insightValue := fmt.Sprintf("[%s] %s: %s", issue.Severity, issue.Type, issue.SourceRule.Name)
-
-		// Determine cloud platform string for description
-		cloudPlatform := "Unknown"
-		if issue.EntitySnapshot.CloudPlatform != nil {
-			cloudPlatform = *issue.EntitySnapshot.CloudPlatform
-		}
-
-		// Create a security insight resource targeting the app user using the externalID
-		insightResource, err := resource.NewResource(
-			fmt.Sprintf("%s - %s", issue.SourceRule.Name, issue.EntitySnapshot.Name),
-			securityInsightResourceType,
-			resourceID,
-			resource.WithSecurityInsightTrait(
-				resource.WithIssue(insightValue),
-				resource.WithIssueSeverity(issue.Severity),
-				resource.WithInsightAppUserTarget("", issue.EntitySnapshot.ExternalID),
-				resource.WithInsightObservedAt(issue.CreatedAt),
-			),
-			resource.WithDescription(fmt.Sprintf(
-				"Wiz Security Issue: %s (Status: %s, Severity: %s) affecting %s resource %s",
-				issue.SourceRule.Name,
-				issue.Status,
-				issue.Severity,
-				cloudPlatform,
-				issue.EntitySnapshot.Name,
-			)),
-		)
+		issueInsights, err := newIssueInsights(&issue)
 		if err != nil {
-			return nil, nil, fmt.Errorf("wiz-connector: failed to create security insight resource: %w", err)
+			return nil, nil, err
 		}
 
-		insights = append(insights, insightResource)
+		insights = append(insights, issueInsights...)
 	}
 
 	// Prepare the sync results with next page token if there are more pages
 	syncResults := &resource.SyncOpResults{}
 	if resp.PageInfo.HasNextPage {
-		syncResults.NextPageToken = resp.PageInfo.EndCursor
+		token.Cursor = resp.PageInfo.EndCursor
+		nextToken, err := json.Marshal(token)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to encode issue page token: %w", err)
+		}
+		syncResults.NextPageToken = string(nextToken)
 	}
 
 	return insights, syncResults, nil
 }
 
+// issuePageToken is the page token of a full issue sync.
+type issuePageToken struct {
+	Cursor string `json:"cursor,omitempty"`
+	// CreatedAfter is the created-after cutoff resolved on the first page, so every page lists the same issues.
+	CreatedAfter *time.Time `json:"created_after,omitempty"`
+}
+
+// Phases of an incremental issue sync.
+const (
+	// issuePhaseFull downloads every issue matching the filter and records it in the state store.
+	issuePhaseFull = "full"
+	// issuePhaseUpdates downloads the issues updated since the watermark and applies them to the state store.
+	issuePhaseUpdates = "updates"
+	// issuePhaseReplay returns the issues recorded in the state store.
+	issuePhaseReplay = "replay"
+)
+
+const (
+	// issueSessionScope is the state store scope of the watermark and issue snapshots.
+	issueSessionScope = "wiz-connector-issues"
+	// issueWatermarkKey holds the issueWatermark of the last completed sync.
+	issueWatermarkKey = "watermark"
+	// issueKeyPrefix prefixes the snapshot of each open issue, keyed by issue ID.
+	issueKeyPrefix = "issue/"
+)
+
+// issueWatermark records when the last completed incremental sync started and the filter it used.
+// Snapshots are only reused while the configured filter is unchanged.
+type issueWatermark struct {
+	SyncedAt time.Time `json:"synced_at"`
+	Filter   string    `json:"filter"`
+}
+
+// issueSyncToken is the page token of an incremental issue sync.
+type issueSyncToken struct {
+	Phase string `json:"phase"`
+	// Cursor is the Relay cursor in the full and updates phases, and the state store page token in the replay phase.
+	Cursor string `json:"cursor,omitempty"`
+	// Started is when the sync started; it becomes the watermark once the sync completes.
+	Started   time.Time `json:"started"`
+	Watermark time.Time `json:"watermark"`
+	// CreatedAfter is the created-after cutoff resolved when the sync started.
+	CreatedAfter *time.Time `json:"created_after,omitempty"`
+}
+
+// listIncremental keeps a snapshot of every open issue in the state store alongside a watermark.
+// The first sync downloads all issues. Later syncs download only the issues updated since the watermark,
+// whatever their status: issues still in one of the configured statuses replace their snapshot, while resolved
+// or rejected issues are removed. The remaining snapshots are then returned as insight resources, so every
+// sync still holds every open issue.
+// The SDK session store is not used, as it lives in the c1z file of a single sync and starts empty on every hosted
+// sync; the state store is a local directory that outlives the sync, see dirSessionStore.
+func (i *insightBuilder) listIncremental(ctx context.Context, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	l := ctxzap.Extract(ctx)
+	store := i.state
+	scope := sessions.WithSyncID(issueSessionScope)
+
+	filterKey, err := json.Marshal(i.filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to encode issue filter: %w", err)
+	}
+
+	token := issueSyncToken{Phase: issuePhaseFull, Started: time.Now().UTC(), CreatedAfter: i.createdAfter()}
+	if attr.PageToken.Token != "" {
+		if err := json.Unmarshal([]byte(attr.PageToken.Token), &token); err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid issue page token: %v", err)
+		}
+	} else {
+		watermark, found, err := session.GetJSON[issueWatermark](ctx, store, issueWatermarkKey, scope)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to read issue watermark: %w", err)
+		}
+		switch {
+		case !found:
+			l.Info("wiz-connector: no issue watermark from a previous sync in the state directory, downloading all issues")
+		case watermark.Filter != string(filterKey):
+			l.Info("wiz-connector: issue filter changed since the previous sync, downloading all issues")
+		default:
+			token.Phase = issuePhaseUpdates
+			token.Watermark = watermark.SyncedAt
+			l.Debug("wiz-connector: downloading issues updated since the previous sync", zap.Time("watermark", watermark.SyncedAt))
+		}
+		if token.Phase == issuePhaseFull {
+			// Drop snapshots left over from an interrupted first sync or taken with another filter
+			if err := store.Clear(ctx, scope); err != nil {
+				return nil, nil, fmt.Errorf("wiz-connector: failed to clear issue snapshots: %w", err)
+			}
+		}
+	}
+
+	var insights []*v2.Resource
+	next := token
+	done := false
+	switch token.Phase {
+	case issuePhaseFull:
+		resp, err := i.client.ListIssues(ctx, i.issueFilter(token.CreatedAfter), relayCursor(token.Cursor))
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to list issues: %w", err)
+		}
+		if insights, err = i.recordIssues(ctx, store, resp.Nodes); err != nil {
+			return nil, nil, err
+		}
+		next.Cursor = resp.PageInfo.EndCursor
+		done = !resp.PageInfo.HasNextPage
+
+	case issuePhaseUpdates:
+		filter := i.issueFilter(token.CreatedAfter)
+		// Resolved and rejected issues must be listed too, to drop their snapshot
+		filter.Statuses = nil
+		filter.UpdatedAfter = &token.Watermark
+		resp, err := i.client.ListIssues(ctx, filter, relayCursor(token.Cursor))
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to list updated issues: %w", err)
+		}
+		if _, err := i.recordIssues(ctx, store, resp.Nodes); err != nil {
+			return nil, nil, err
+		}
+		next.Cursor = resp.PageInfo.EndCursor
+		if !resp.PageInfo.HasNextPage {
+			next.Phase, next.Cursor = issuePhaseReplay, ""
+		}
+
+	case issuePhaseReplay:
+		snapshots, pageToken, err := store.GetAll(ctx, token.Cursor, scope, sessions.WithPrefix(issueKeyPrefix))
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to read issue snapshots: %w", err)
+		}
+		if insights, err = i.replayIssues(ctx, store, snapshots, token.CreatedAfter); err != nil {
+			return nil, nil, err
+		}
+		next.Cursor = pageToken
+		done = pageToken == ""
+
+	default:
+		return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid issue sync phase %q", token.Phase)
+	}
+
+	if done {
+		watermark := issueWatermark{SyncedAt: token.Started, Filter: string(filterKey)}
+		if err := session.SetJSON(ctx, store, issueWatermarkKey, watermark, scope); err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to save issue watermark: %w", err)
+		}
+		return insights, &resource.SyncOpResults{}, nil
+	}
+
+	nextToken, err := json.Marshal(next)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to encode issue page token: %w", err)
+	}
+	return insights, &resource.SyncOpResults{NextPageToken: string(nextToken)}, nil
+}
+
+// recordIssues applies downloaded issues to the state store: issues in one of the configured statuses replace
+// their snapshot and are returned as insight resources, any other issue has its snapshot removed.
+func (i *insightBuilder) recordIssues(ctx context.Context, store sessions.SessionStore, issues []wiz.Issue) ([]*v2.Resource, error) {
+	scope := sessions.WithSyncID(issueSessionScope)
+
+	var insights []*v2.Resource
+	snapshots := make(map[string]wiz.Issue)
+	for _, issue := range issues {
+		if issue.EntitySnapshot.ExternalID == "" || issue.ID == "" {
+			continue
+		}
+
+		if len(i.filter.Statuses) > 0 && !slices.Contains(i.filter.Statuses, issue.Status) {
+			if err := store.Delete(ctx, issueKeyPrefix+issue.ID, scope); err != nil {
+				return nil, fmt.Errorf("wiz-connector: failed to remove issue snapshot %s: %w", issue.ID, err)
+			}
+			continue
+		}
+
+		issueInsights, err := newIssueInsights(&issue)
+		if err != nil {
+			return nil, err
+		}
+		insights = append(insights, issueInsights...)
+		snapshots[issueKeyPrefix+issue.ID] = issue
+	}
+
+	if err := session.SetManyJSON(ctx, store, snapshots, scope); err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to save issue snapshots: %w", err)
+	}
+
+	return insights, nil
+}
+
+// replayIssues returns the insight resources of stored issue snapshots.
+// Snapshots of issues created before the created-after cutoff are removed instead.
+func (i *insightBuilder) replayIssues(ctx context.Context, store sessions.SessionStore, snapshots map[string][]byte, createdAfter *time.Time) ([]*v2.Resource, error) {
+	keys := slices.Sorted(maps.Keys(snapshots))
+	insights := make([]*v2.Resource, 0, 2*len(keys))
+	for _, key := range keys {
+		var issue wiz.Issue
+		if err := json.Unmarshal(snapshots[key], &issue); err != nil {
+			return nil, fmt.Errorf("wiz-connector: failed to decode issue snapshot %s: %w", key, err)
+		}
+
+		if createdAfter != nil && !issue.CreatedAt.After(*createdAfter) {
+			if err := store.Delete(ctx, issueKeyPrefix+issue.ID, sessions.WithSyncID(issueSessionScope)); err != nil {
+				return nil, fmt.Errorf("wiz-connector: failed to remove issue snapshot %s: %w", issue.ID, err)
+			}
+			continue
+		}
+
+		issueInsights, err := newIssueInsights(&issue)
+		if err != nil {
+			return nil, err
+		}
+		insights = append(insights, issueInsights...)
+	}
+
+	return insights, nil
+}
+
+func relayCursor(cursor string) *string {
+	if cursor == "" {
+		return nil
+	}
+	return &cursor
+}
+
+// App hints that let Uplift resolve external resource targets to the apps synced by baton-aws, baton-azure and baton-gcp.
+const (
+	insightAppHintAWS   = "aws"
+	insightAppHintAzure = "azure"
+	insightAppHintGCP   = "gcp"
+)
+
+// insightTarget chooses the target of the insight on the entity an issue affects, from its type and cloud platform:
+//   - AWS IAM users, roles, groups and access keys are external resources identified by ARN
+//   - GCP service accounts are external resources identified by email
+//   - Azure service principals, managed identities and groups are external resources identified by Entra ID object ID
+//   - Human accounts in Okta, Entra ID or Google Workspace are C1 users identified by email
+//
+// Entities that are not recognized keep an app user target on the external ID.
+func insightTarget(entity *wiz.EntitySnapshot) resource.SecurityInsightTraitOption {
+	externalID := entity.ExternalID
+	platform := ""
+	if entity.CloudPlatform != nil {
+		platform = strings.ToLower(*entity.CloudPlatform)
+	}
+	isUser := entity.Type == "USER_ACCOUNT"
+
+	switch {
+	case platform == "aws" || strings.HasPrefix(externalID, "arn:aws"):
+		return resource.WithInsightExternalResourceTarget(externalID, insightAppHintAWS)
+
+	case platform == "gcp":
+		if email := entityEmail(entity); isUser && email != "" {
+			return resource.WithInsightUserTarget(email)
+		}
+		return resource.WithInsightExternalResourceTarget(externalID, insightAppHintGCP)
+
+	case platform == "azure" || platform == "azuread" || platform == "entraid":
+		if email := entityEmail(entity); isUser && email != "" {
+			return resource.WithInsightUserTarget(email)
+		}
+		return resource.WithInsightExternalResourceTarget(externalID, insightAppHintAzure)
+
+	case platform == "okta":
+		if email := entityEmail(entity); isUser && email != "" {
+			return resource.WithInsightUserTarget(email)
+		}
+	}
+
+	return resource.WithInsightAppUserTarget("", externalID)
+}
+
+// entityEmail returns the email of an identity entity. Identity providers record it as the external ID or the name.
+func entityEmail(entity *wiz.EntitySnapshot) string {
+	for _, candidate := range []string{entity.ExternalID, entity.Name} {
+		if strings.Contains(candidate, "@") {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// newIssueInsights returns the insights of an issue: the issue itself and its risk score.
+// The insight type of the SDK's security insight trait is a oneof, so one resource cannot carry both the severity
+// of an issue and a risk score; the score is synced as a second resource on the same target.
+func newIssueInsights(issue *wiz.Issue) ([]*v2.Resource, error) {
+	issueResource, err := newInsightResource(issue)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to create security insight resource: %w", err)
+	}
+	riskScoreResource, err := newRiskScoreResource(issue)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to create risk score resource: %w", err)
+	}
+	return []*v2.Resource{issueResource, riskScoreResource}, nil
+}
+
+// newInsightResource creates a security insight resource targeting the entity affected by the issue, see insightTarget.
+func newInsightResource(issue *wiz.Issue) (*v2.Resource, error) {
+	// Create a unique resource ID combining issue ID and external resource ID
+	resourceID := fmt.Sprintf("%s:%s", issue.ID, issue.EntitySnapshot.ExternalID)
+
+	// Create the insight value with severity and rule name
+	insightValue := fmt.Sprintf("[%s] %s: %s", issue.Severity, issue.Type, issue.SourceRule.Name)
+
+	return resource.NewResource(
+		fmt.Sprintf("%s - %s", issue.SourceRule.Name, issue.EntitySnapshot.Name),
+		securityInsightResourceType,
+		resourceID,
+		resource.WithSecurityInsightTrait(
+			resource.WithIssue(insightValue),
+			resource.WithIssueSeverity(issue.Severity),
+			insightTarget(&issue.EntitySnapshot),
+			resource.WithInsightObservedAt(issue.CreatedAt),
+		),
+		resource.WithDescription(issueDescription(issue)),
+		resource.WithAnnotation(issueProjectRefs(issue)...),
+	)
+}
+
+// newRiskScoreResource creates the risk score insight of an issue, on the same target as the issue insight.
+func newRiskScoreResource(issue *wiz.Issue) (*v2.Resource, error) {
+	resourceID := fmt.Sprintf("%s:%s:risk-score", issue.ID, issue.EntitySnapshot.ExternalID)
+
+	return resource.NewResource(
+		fmt.Sprintf("Risk score: %s - %s", issue.SourceRule.Name, issue.EntitySnapshot.Name),
+		securityInsightResourceType,
+		resourceID,
+		resource.WithSecurityInsightTrait(
+			resource.WithRiskScore(strconv.Itoa(issueRiskScore(issue))),
+			insightTarget(&issue.EntitySnapshot),
+			resource.WithInsightObservedAt(issue.UpdatedAt),
+		),
+		resource.WithDescription(fmt.Sprintf(
+			"Risk score computed by the connector from the severity, type and project business impact of Wiz issue %s affecting %s",
+			issue.ID,
+			issue.EntitySnapshot.Name,
+		)),
+		resource.WithAnnotation(issueProjectRefs(issue)...),
+	)
+}
+
+// Risk score of each issue severity, before the adjustments made in issueRiskScore.
+var severityRiskScores = map[string]int{
+	"INFORMATIONAL": 10,
+	"LOW":           25,
+	"MEDIUM":        50,
+	"HIGH":          75,
+	"CRITICAL":      90,
+}
+
+// issueRiskScore computes a heuristic score from 0 to 100 for an issue, as Wiz does not expose one.
+// The score starts from the issue severity, and is raised by 10 for toxic combinations, which chain several risks
+// on the same entity, and by 10 or 5 when one of the issue's projects has a high or medium business impact.
+func issueRiskScore(issue *wiz.Issue) int {
+	score := severityRiskScores[issue.Severity]
+	if issue.Type == "TOXIC_COMBINATION" {
+		score += 10
+	}
+
+	impact := 0
+	for _, project := range issue.Projects {
+		switch project.RiskProfile.BusinessImpact {
+		case "HBI":
+			impact = max(impact, 10)
+		case "MBI":
+			impact = max(impact, 5)
+		}
+	}
+
+	return min(score+impact, 100)
+}
+
+// issueProjectRefs returns the IDs of the project resources an issue belongs to, stored on its insights so each
+// insight links to the synced projects.
+func issueProjectRefs(issue *wiz.Issue) []proto.Message {
+	refs := make([]proto.Message, 0, len(issue.Projects))
+	for _, project := range issue.Projects {
+		refs = append(refs, &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: project.ID})
+	}
+	return refs
+}
+
+// maxDescriptionLength is the longest resource description the SDK accepts.
+const maxDescriptionLength = 2048
+
+// issueDescription summarizes an issue with its projects, due date, tickets, latest note, evidence, remediation and
+// the description of the Wiz control that raised it.
+func issueDescription(issue *wiz.Issue) string {
+	// Determine cloud platform string for description
+	cloudPlatform := "Unknown"
+	if issue.EntitySnapshot.CloudPlatform != nil {
+		cloudPlatform = *issue.EntitySnapshot.CloudPlatform
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Wiz Security Issue: %s (Status: %s, Severity: %s) affecting %s resource %s",
+		issue.SourceRule.Name,
+		issue.Status,
+		issue.Severity,
+		cloudPlatform,
+		issue.EntitySnapshot.Name,
+	)
+	if len(issue.Projects) > 0 {
+		projects := make([]string, 0, len(issue.Projects))
+		for _, project := range issue.Projects {
+			name := project.Name
+			if name == "" {
+				name = project.ID
+			}
+			projects = append(projects, name)
+		}
+		fmt.Fprintf(&b, ". Projects: %s", strings.Join(projects, ", "))
+	}
+	if issue.DueAt != nil {
+		fmt.Fprintf(&b, ". Due %s", issue.DueAt.Format(time.DateOnly))
+	}
+	if issue.ResolvedAt != nil {
+		fmt.Fprintf(&b, ". Resolved %s", issue.ResolvedAt.Format(time.DateOnly))
+	}
+	if len(issue.ServiceTickets) > 0 {
+		tickets := make([]string, 0, len(issue.ServiceTickets))
+		for _, ticket := range issue.ServiceTickets {
+			tickets = append(tickets, ticket.Name)
+		}
+		fmt.Fprintf(&b, ". Tickets: %s", strings.Join(tickets, ", "))
+	}
+	if len(issue.SourceRule.SecuritySubCategories) > 0 {
+		controls := make([]string, 0, len(issue.SourceRule.SecuritySubCategories))
+		for _, subCategory := range issue.SourceRule.SecuritySubCategories {
+			controls = append(controls, subCategory.Title)
+		}
+		fmt.Fprintf(&b, ". Controls: %s", strings.Join(controls, "; "))
+	}
+	if len(issue.Notes) > 0 {
+		fmt.Fprintf(&b, ". Latest note: %s", issue.Notes[len(issue.Notes)-1].Text)
+	}
+	if len(issue.Evidence) > 0 {
+		evidence := make([]string, 0, len(issue.Evidence))
+		for _, e := range issue.Evidence {
+			if e.Description != "" {
+				evidence = append(evidence, fmt.Sprintf("%s (%s)", e.Name, e.Description))
+			} else {
+				evidence = append(evidence, e.Name)
+			}
+		}
+		fmt.Fprintf(&b, ". Evidence: %s", strings.Join(evidence, "; "))
+	}
+	if issue.SourceRule.ResolutionRecommendation != "" {
+		fmt.Fprintf(&b, ". Remediation: %s", issue.SourceRule.ResolutionRecommendation)
+	}
+	if issue.SourceRule.ControlDescription != "" {
+		// The control description is the longest part, so it goes last to be the one cut when too long
+		fmt.Fprintf(&b, ". Control: %s", issue.SourceRule.ControlDescription)
+	}
+
+	description := b.String()
+	if len(description) > maxDescriptionLength {
+		// Cut on a rune boundary
+		description = strings.ToValidUTF8(description[:maxDescriptionLength], "")
+	}
+	return description
+}
+
 // Entitlements returns an empty slice as security insights are informational resources.
 func (i *insightBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
 	return nil, nil, nil
@@ -99,6 +562,246 @@ func (i *insightBuilder) Grants(ctx context.Context, resource *v2.Resource, attr
 	return nil, nil, nil
 }
 
-func newInsightBuilder(client wiz.Client) *insightBuilder {
-	return &insightBuilder{client: client}
+// issueFilter returns the configured filter limited to the issues created after the given cutoff, if any.
+func (i *insightBuilder) issueFilter(createdAfter *time.Time) wiz.IssueFilter {
+	filter := i.filter
+	filter.CreatedAfter = createdAfter
+	return filter
+}
+
+// createdAfter resolves the created-within window to a cutoff, or nil when issues are not limited by age.
+// It is resolved once when a sync starts and carried in the page token, see issuePageToken.
+func (i *insightBuilder) createdAfter() *time.Time {
+	if i.createdWithin <= 0 {
+		return nil
+	}
+	createdAfter := time.Now().UTC().Add(-i.createdWithin)
+	return &createdAfter
+}
+
+// ResourceActions registers the actions that close the loop on Wiz issues from ConductorOne: resolving or
+// rejecting an issue, adding a note to it, and setting its due date.
+func (i *insightBuilder) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	if err := registry.Register(ctx, resolveIssueActionSchema, i.resolveIssue); err != nil {
+		return err
+	}
+	if err := registry.Register(ctx, rejectIssueActionSchema, i.rejectIssue); err != nil {
+		return err
+	}
+	if err := registry.Register(ctx, addIssueNoteActionSchema, i.addIssueNote); err != nil {
+		return err
+	}
+	return registry.Register(ctx, setIssueDueDateActionSchema, i.setIssueDueDate)
+}
+
+// insightResourceArg is the security insight argument shared by the issue actions.
+var insightResourceArg = config.Field_builder{
+	Name:            "resource",
+	DisplayName:     "Security Insight",
+	Description:     "The security insight of the Wiz issue",
+	IsRequired:      true,
+	ResourceIdField: &config.ResourceIdField{},
+}.Build()
+
+var issueActionReturnTypes = []*config.Field{
+	config.Field_builder{Name: "success", BoolField: &config.BoolField{}}.Build(),
+	config.Field_builder{Name: "issue_id", StringField: &config.StringField{}}.Build(),
+	config.Field_builder{Name: "status", StringField: &config.StringField{}}.Build(),
+}
+
+var resolveIssueActionSchema = v2.BatonActionSchema_builder{
+	Name:        "resolve_issue",
+	DisplayName: "Resolve Issue",
+	Description: "Resolve the Wiz issue of a security insight as fixed, e.g. once an access review removed the risky permission",
+	Arguments: []*config.Field{
+		insightResourceArg,
+		config.Field_builder{
+			Name:        "note",
+			DisplayName: "Note",
+			Description: "Resolution note recorded on the issue",
+			StringField: &config.StringField{},
+		}.Build(),
+	},
+	ReturnTypes: issueActionReturnTypes,
+	ActionType:  []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+var rejectIssueActionSchema = v2.BatonActionSchema_builder{
+	Name:        "reject_issue",
+	DisplayName: "Reject Issue",
+	Description: "Reject the Wiz issue of a security insight as a false positive, an accepted exception, or a risk that will not be fixed",
+	Arguments: []*config.Field{
+		insightResourceArg,
+		config.Field_builder{
+			Name:        "reason",
+			DisplayName: "Reason",
+			Description: "Why the issue is rejected",
+			IsRequired:  true,
+			StringField: config.StringField_builder{
+				Options: []*config.StringFieldOption{
+					config.StringFieldOption_builder{Value: "FALSE_POSITIVE", DisplayName: "False positive"}.Build(),
+					config.StringFieldOption_builder{Value: "EXCEPTION", DisplayName: "Exception"}.Build(),
+					config.StringFieldOption_builder{Value: "WONT_FIX", DisplayName: "Won't fix"}.Build(),
+				},
+			}.Build(),
+		}.Build(),
+		config.Field_builder{
+			Name:        "note",
+			DisplayName: "Note",
+			Description: "Justification recorded on the issue",
+			StringField: &config.StringField{},
+		}.Build(),
+	},
+	ReturnTypes: issueActionReturnTypes,
+	ActionType:  []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+var addIssueNoteActionSchema = v2.BatonActionSchema_builder{
+	Name:        "add_issue_note",
+	DisplayName: "Add Issue Note",
+	Description: "Add a note to the Wiz issue of a security insight",
+	Arguments: []*config.Field{
+		insightResourceArg,
+		config.Field_builder{
+			Name:        "text",
+			DisplayName: "Text",
+			Description: "Text of the note",
+			IsRequired:  true,
+			StringField: &config.StringField{},
+		}.Build(),
+	},
+	ReturnTypes: issueActionReturnTypes,
+	ActionType:  []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+var setIssueDueDateActionSchema = v2.BatonActionSchema_builder{
+	Name:        "set_issue_due_date",
+	DisplayName: "Set Issue Due Date",
+	Description: "Set the date by which the Wiz issue of a security insight must be addressed",
+	Arguments: []*config.Field{
+		insightResourceArg,
+		config.Field_builder{
+			Name:        "due_date",
+			DisplayName: "Due Date",
+			Description: "Due date as YYYY-MM-DD, or an RFC 3339 timestamp",
+			IsRequired:  true,
+			Placeholder: "2025-12-31",
+			StringField: &config.StringField{},
+		}.Build(),
+	},
+	ReturnTypes: issueActionReturnTypes,
+	ActionType:  []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+// resolveIssue handles the resolve_issue action.
+func (i *insightBuilder) resolveIssue(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	resolved, fixed := "RESOLVED", "ISSUE_FIXED"
+	patch := wiz.UpdateIssuePatch{
+		Status:           &resolved,
+		ResolutionReason: &fixed,
+	}
+	if note, ok := actions.GetStringArg(args, "note"); ok && note != "" {
+		patch.Note = &note
+	}
+	return i.updateIssue(ctx, args, patch)
+}
+
+// rejectIssue handles the reject_issue action.
+func (i *insightBuilder) rejectIssue(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	reason, err := actions.RequireStringArg(args, "reason")
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: %s", err)
+	}
+	if !slices.Contains(wiz.IssueRejectionReasons, reason) {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid rejection reason %q, expected one of %v", reason, wiz.IssueRejectionReasons)
+	}
+
+	rejected := "REJECTED"
+	patch := wiz.UpdateIssuePatch{
+		Status:           &rejected,
+		ResolutionReason: &reason,
+	}
+	if note, ok := actions.GetStringArg(args, "note"); ok && note != "" {
+		patch.Note = &note
+	}
+	return i.updateIssue(ctx, args, patch)
+}
+
+// setIssueDueDate handles the set_issue_due_date action.
+func (i *insightBuilder) setIssueDueDate(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	value, err := actions.RequireStringArg(args, "due_date")
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: %s", err)
+	}
+	dueAt, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		if dueAt, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid due date %q, expected YYYY-MM-DD or an RFC 3339 timestamp", value)
+		}
+	}
+
+	return i.updateIssue(ctx, args, wiz.UpdateIssuePatch{DueAt: &dueAt})
+}
+
+// updateIssue applies patch to the issue of the security insight in the resource argument.
+func (i *insightBuilder) updateIssue(ctx context.Context, args *structpb.Struct, patch wiz.UpdateIssuePatch) (*structpb.Struct, annotations.Annotations, error) {
+	issueID, err := insightIssueID(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	issue, err := i.client.UpdateIssue(ctx, issueID, patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to update issue %s: %w", issueID, err)
+	}
+
+	return actions.NewReturnValues(true,
+		actions.NewStringReturnField("issue_id", issue.ID),
+		actions.NewStringReturnField("status", issue.Status),
+	), nil, nil
+}
+
+// addIssueNote handles the add_issue_note action.
+func (i *insightBuilder) addIssueNote(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	issueID, err := insightIssueID(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	text, err := actions.RequireStringArg(args, "text")
+	if err != nil || text == "" {
+		return nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: the text of the note is required")
+	}
+
+	if _, err := i.client.CreateIssueNote(ctx, issueID, text); err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to add note to issue %s: %w", issueID, err)
+	}
+
+	return actions.NewReturnValues(true, actions.NewStringReturnField("issue_id", issueID)), nil, nil
+}
+
+// insightIssueID returns the Wiz issue ID of the security insight in the resource argument.
+// Insight resource IDs start with the issue ID, see newInsightResource and newRiskScoreResource.
+func insightIssueID(args *structpb.Struct) (string, error) {
+	resourceID, err := actions.RequireResourceIDArg(args, "resource")
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "wiz-connector: %s", err)
+	}
+	if resourceID.GetResourceType() != securityInsightResourceType.Id {
+		return "", status.Errorf(codes.InvalidArgument, "wiz-connector: expected a %s resource, got %s", securityInsightResourceType.Id, resourceID.GetResourceType())
+	}
+
+	issueID, _, _ := strings.Cut(resourceID.GetResource(), ":")
+	if issueID == "" {
+		return "", status.Errorf(codes.InvalidArgument, "wiz-connector: invalid security insight ID %q", resourceID.GetResource())
+	}
+	return issueID, nil
+}
+
+func newInsightBuilder(client wiz.Client, filter wiz.IssueFilter, createdWithin time.Duration, state sessions.SessionStore) *insightBuilder {
+	return &insightBuilder{
+		client:        client,
+		filter:        filter,
+		createdWithin: createdWithin,
+		state:         state,
+	}
 }
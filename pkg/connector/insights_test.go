@@ -0,0 +1,376 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestInsightListAppliesIssueFilter(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.pageSize = 1
+	client.issues = []wiz.Issue{
+		{ID: "issue-1", Severity: "HIGH", EntitySnapshot: wiz.EntitySnapshot{ExternalID: "alice"}},
+		{ID: "issue-2", Severity: "CRITICAL", EntitySnapshot: wiz.EntitySnapshot{ExternalID: "bob"}},
+	}
+	filter := wiz.IssueFilter{
+		Statuses:    wiz.DefaultIssueStatuses,
+		EntityTypes: wiz.DefaultIssueEntityTypes,
+		MinSeverity: "HIGH",
+	}
+	builder := newInsightBuilder(client, filter, 7*24*time.Hour, nil)
+
+	_, results, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = builder.List(ctx, nil, resource.SyncOpAttrs{PageToken: pagination.Token{Token: results.NextPageToken}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !assert.Len(t, client.issueFilters, 2) {
+		return
+	}
+	got := client.issueFilters[0]
+	assert.Equal(t, filter.Statuses, got.Statuses)
+	assert.Equal(t, "HIGH", got.MinSeverity)
+	if assert.NotNil(t, got.CreatedAfter) {
+		// The cutoff is resolved on the first page and identical for every page.
+		assert.WithinDuration(t, time.Now().Add(-7*24*time.Hour), *got.CreatedAfter, time.Minute)
+		if assert.NotNil(t, client.issueFilters[1].CreatedAfter) {
+			assert.True(t, got.CreatedAfter.Equal(*client.issueFilters[1].CreatedAfter))
+		}
+	}
+}
+
+func TestInsightIncrementalSync(t *testing.T) {
+	client := newFakeClient()
+	client.pageSize = 1
+	synced := time.Now().Add(-time.Hour)
+	client.issues = []wiz.Issue{
+		{ID: "issue-1", Status: "OPEN", UpdatedAt: synced, EntitySnapshot: wiz.EntitySnapshot{ExternalID: "alice"}},
+		{ID: "issue-2", Status: "OPEN", UpdatedAt: synced, EntitySnapshot: wiz.EntitySnapshot{ExternalID: "bob"}},
+	}
+	// Each sync runs with a new store on the same directory, as a new connector process would.
+	dir := t.TempDir()
+	newBuilder := func(filter wiz.IssueFilter, createdWithin time.Duration) *insightBuilder {
+		store, err := newDirSessionStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newInsightBuilder(client, filter, createdWithin, store)
+	}
+
+	// The first sync downloads every issue.
+	assert.ElementsMatch(t, []string{"issue-1:alice", "issue-2:bob"}, listInsightIDs(t, newBuilder(wiz.IssueFilter{Statuses: wiz.DefaultIssueStatuses}, 0)))
+	assert.Equal(t, 2, client.callCount("ListIssues"))
+
+	// issue-1 is resolved and issue-3 opened after the first sync; issue-2 is unchanged.
+	updated := time.Now().Add(time.Hour)
+	client.issues[0].Status = "RESOLVED"
+	client.issues[0].UpdatedAt = updated
+	client.issues = append(client.issues, wiz.Issue{
+		ID: "issue-3", Status: "IN_PROGRESS", UpdatedAt: updated, EntitySnapshot: wiz.EntitySnapshot{ExternalID: "carol"},
+	})
+	client.issueFilters = nil
+
+	assert.ElementsMatch(t, []string{"issue-2:bob", "issue-3:carol"}, listInsightIDs(t, newBuilder(wiz.IssueFilter{Statuses: wiz.DefaultIssueStatuses}, 0)))
+	// Only the two updated issues are downloaded, whatever their status.
+	if assert.Len(t, client.issueFilters, 2) {
+		assert.Nil(t, client.issueFilters[0].Statuses)
+		assert.NotNil(t, client.issueFilters[0].UpdatedAfter)
+	}
+
+	t.Run("a changed filter triggers a full sync", func(t *testing.T) {
+		client.issueFilters = nil
+		filtered := newBuilder(wiz.IssueFilter{Statuses: []string{"OPEN"}}, 0)
+
+		assert.ElementsMatch(t, []string{"issue-2:bob"}, listInsightIDs(t, filtered))
+		if assert.NotEmpty(t, client.issueFilters) {
+			assert.Nil(t, client.issueFilters[0].UpdatedAfter)
+		}
+	})
+
+	t.Run("the created-after cutoff is fixed for the whole sync", func(t *testing.T) {
+		client.issueFilters = nil
+		windowed := newBuilder(wiz.IssueFilter{Statuses: []string{"IN_PROGRESS"}}, 7*24*time.Hour)
+
+		listInsightIDs(t, windowed)
+		if !assert.Len(t, client.issueFilters, 3) {
+			return
+		}
+		createdAfter := client.issueFilters[0].CreatedAfter
+		if assert.NotNil(t, createdAfter) {
+			for _, filter := range client.issueFilters[1:] {
+				assert.True(t, createdAfter.Equal(*filter.CreatedAfter))
+			}
+		}
+	})
+}
+
+func TestIssueInsights(t *testing.T) {
+	dueAt := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	issue := &wiz.Issue{
+		ID:             "issue-1",
+		Type:           "TOXIC_COMBINATION",
+		Severity:       "HIGH",
+		Status:         "OPEN",
+		DueAt:          &dueAt,
+		SourceRule:     wiz.SourceRule{Name: "Admin user without MFA", ControlDescription: "Admins can take over the account", ResolutionRecommendation: "Enable MFA"},
+		EntitySnapshot: wiz.EntitySnapshot{Type: "USER_ACCOUNT", ExternalID: "arn:aws:iam::123456789012:user/alice", Name: "alice"},
+		Projects: []wiz.IssueProject{
+			{ID: "project-1", Name: "Payments", RiskProfile: wiz.ProjectRiskProfile{BusinessImpact: "MBI"}},
+			{ID: "project-2"},
+		},
+		ServiceTickets: []wiz.ServiceTicket{{Name: "SEC-42"}},
+		Evidence:       []wiz.IssueEvidence{{Name: "Administrator access", Description: "AdministratorAccess attached"}, {Name: "No MFA device"}},
+	}
+
+	insights, err := newIssueInsights(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, insights, 2) {
+		return
+	}
+
+	issueTrait, err := resource.GetSecurityInsightTrait(insights[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, resource.IsIssue(issueTrait))
+	assert.Contains(t, insights[0].GetDescription(), "Due 2025-09-01")
+	assert.Contains(t, insights[0].GetDescription(), "Tickets: SEC-42")
+	assert.Contains(t, insights[0].GetDescription(), "Remediation: Enable MFA")
+	assert.Contains(t, insights[0].GetDescription(), "Control: Admins can take over the account")
+	// Projects without a name are shown by ID.
+	assert.Contains(t, insights[0].GetDescription(), "Projects: Payments, project-2")
+	assert.Contains(t, insights[0].GetDescription(), "Evidence: Administrator access (AdministratorAccess attached); No MFA device")
+
+	// Both insights link to the synced project resources.
+	for _, insight := range insights {
+		var projectIDs []string
+		for _, a := range insight.GetAnnotations() {
+			ref := &v2.ResourceId{}
+			if a.MessageIs(ref) {
+				if err := a.UnmarshalTo(ref); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, projectResourceType.Id, ref.GetResourceType())
+				projectIDs = append(projectIDs, ref.GetResource())
+			}
+		}
+		assert.Equal(t, []string{"project-1", "project-2"}, projectIDs)
+	}
+
+	// HIGH is 75, raised by 10 for the toxic combination and 5 for the medium business impact project.
+	riskTrait, err := resource.GetSecurityInsightTrait(insights[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, resource.IsRiskScore(riskTrait))
+	assert.Equal(t, "90", resource.GetInsightValue(riskTrait))
+	assert.Equal(t, resource.GetExternalResourceTargetId(issueTrait), resource.GetExternalResourceTargetId(riskTrait))
+}
+
+func TestInsightActions(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.issues = []wiz.Issue{{ID: "issue-1", Status: "OPEN", EntitySnapshot: wiz.EntitySnapshot{ExternalID: "arn:aws:iam::123456789012:user/alice"}}}
+	builder := newInsightBuilder(client, wiz.IssueFilter{}, 0, nil)
+
+	actionArgs := func(t *testing.T, fields map[string]interface{}) *structpb.Struct {
+		t.Helper()
+		// Resource IDs of insights on AWS entities contain colons after the issue ID.
+		fields["resource"] = map[string]interface{}{
+			"resource_type_id": securityInsightResourceType.Id,
+			"resource_id":      "issue-1:arn:aws:iam::123456789012:user/alice",
+		}
+		args, err := structpb.NewStruct(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return args
+	}
+
+	t.Run("reject requires a known reason", func(t *testing.T) {
+		_, _, err := builder.rejectIssue(ctx, actionArgs(t, map[string]interface{}{"reason": "NOT_MY_PROBLEM"}))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 0, client.callCount("UpdateIssue"))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		result, _, err := builder.rejectIssue(ctx, actionArgs(t, map[string]interface{}{"reason": "EXCEPTION", "note": "Approved by security"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result.GetFields()["success"].GetBoolValue())
+		assert.Equal(t, "REJECTED", result.GetFields()["status"].GetStringValue())
+		assert.Equal(t, "EXCEPTION", *client.issueReasons[0])
+		assert.Equal(t, "Approved by security", client.issues[0].Notes[0].Text)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		result, _, err := builder.resolveIssue(ctx, actionArgs(t, map[string]interface{}{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "RESOLVED", result.GetFields()["status"].GetStringValue())
+		assert.Equal(t, "ISSUE_FIXED", *client.issueReasons[1])
+	})
+
+	t.Run("add note", func(t *testing.T) {
+		_, _, err := builder.addIssueNote(ctx, actionArgs(t, map[string]interface{}{"text": "Access revoked in review"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Access revoked in review", client.issues[0].Notes[1].Text)
+	})
+
+	t.Run("set due date", func(t *testing.T) {
+		_, _, err := builder.setIssueDueDate(ctx, actionArgs(t, map[string]interface{}{"due_date": "2025-12-31"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assert.NotNil(t, client.issues[0].DueAt) {
+			assert.Equal(t, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), *client.issues[0].DueAt)
+		}
+
+		_, _, err = builder.setIssueDueDate(ctx, actionArgs(t, map[string]interface{}{"due_date": "next week"}))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("other resource types are refused", func(t *testing.T) {
+		args, err := structpb.NewStruct(map[string]interface{}{
+			"resource": map[string]interface{}{"resource_type_id": userResourceType.Id, "resource_id": "alice@example.com"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = builder.resolveIssue(ctx, args)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+// listInsightIDs runs a complete insight listing and returns the resource IDs of the issue insights.
+func listInsightIDs(t *testing.T, builder *insightBuilder) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	var ids []string
+	token := ""
+	for {
+		insights, results, err := builder.List(ctx, nil, resource.SyncOpAttrs{PageToken: pagination.Token{Token: token}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, insight := range insights {
+			trait, err := resource.GetSecurityInsightTrait(insight)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resource.IsIssue(trait) {
+				ids = append(ids, insight.GetId().GetResource())
+			}
+		}
+		if results.NextPageToken == "" {
+			return ids
+		}
+		token = results.NextPageToken
+	}
+}
+
+func TestInsightTarget(t *testing.T) {
+	platform := func(p string) *string { return &p }
+
+	tests := []struct {
+		name       string
+		entity     wiz.EntitySnapshot
+		userEmail  string
+		externalID string
+		appHint    string
+	}{
+		{
+			name:       "aws iam user",
+			entity:     wiz.EntitySnapshot{Type: "USER_ACCOUNT", CloudPlatform: platform("AWS"), ExternalID: "arn:aws:iam::123456789012:user/alice"},
+			externalID: "arn:aws:iam::123456789012:user/alice",
+			appHint:    "aws",
+		},
+		{
+			name:       "aws arn without platform",
+			entity:     wiz.EntitySnapshot{Type: "ROLE", ExternalID: "arn:aws:iam::123456789012:role/deploy"},
+			externalID: "arn:aws:iam::123456789012:role/deploy",
+			appHint:    "aws",
+		},
+		{
+			name:       "gcp service account",
+			entity:     wiz.EntitySnapshot{Type: "SERVICE_ACCOUNT", CloudPlatform: platform("GCP"), ExternalID: "deploy@project.iam.gserviceaccount.com"},
+			externalID: "deploy@project.iam.gserviceaccount.com",
+			appHint:    "gcp",
+		},
+		{
+			name:      "gcp user",
+			entity:    wiz.EntitySnapshot{Type: "USER_ACCOUNT", CloudPlatform: platform("GCP"), ExternalID: "alice@example.com"},
+			userEmail: "alice@example.com",
+		},
+		{
+			name:       "azure service principal",
+			entity:     wiz.EntitySnapshot{Type: "SERVICE_ACCOUNT", CloudPlatform: platform("Azure"), ExternalID: "0b4c2d3e-1f5a-4b6c-8d7e-9f0a1b2c3d4e"},
+			externalID: "0b4c2d3e-1f5a-4b6c-8d7e-9f0a1b2c3d4e",
+			appHint:    "azure",
+		},
+		{
+			name:      "entra id user",
+			entity:    wiz.EntitySnapshot{Type: "USER_ACCOUNT", CloudPlatform: platform("AzureAD"), ExternalID: "0b4c2d3e", Name: "bob@example.com"},
+			userEmail: "bob@example.com",
+		},
+		{
+			name:      "okta user",
+			entity:    wiz.EntitySnapshot{Type: "USER_ACCOUNT", CloudPlatform: platform("Okta"), ExternalID: "00u1abcd", Name: "carol@example.com"},
+			userEmail: "carol@example.com",
+		},
+		{
+			name:       "unknown platform",
+			entity:     wiz.EntitySnapshot{Type: "USER_ACCOUNT", ExternalID: "entity-3"},
+			externalID: "entity-3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := newInsightResource(&wiz.Issue{ID: "issue", Severity: "HIGH", EntitySnapshot: tt.entity})
+			if err != nil {
+				t.Fatal(err)
+			}
+			trait, err := resource.GetSecurityInsightTrait(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch {
+			case tt.userEmail != "":
+				assert.True(t, resource.IsUserTarget(trait))
+				assert.Equal(t, tt.userEmail, resource.GetUserTargetEmail(trait))
+			case tt.appHint != "":
+				assert.True(t, resource.IsExternalResourceTarget(trait))
+				assert.Equal(t, tt.externalID, resource.GetExternalResourceTargetId(trait))
+				assert.Equal(t, tt.appHint, resource.GetExternalResourceTargetAppHint(trait))
+			default:
+				assert.Equal(t, tt.externalID, resource.GetAppUserTargetExternalId(trait))
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+)
+
+type permissionBuilder struct {
+	client wiz.Client
+}
+
+func (p *permissionBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return permissionResourceType
+}
+
+// List returns each distinct scope held by a Wiz service account or role as a permission resource.
+// Wiz has no endpoint listing scopes, so they are collected from all service accounts and roles in a single call.
+// Without read:service_accounts, only the scopes of roles are listed.
+func (p *permissionBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	var permissions []*v2.Resource
+
+	seen := make(map[string]struct{})
+	var scopes []string
+
+	addScopes := func(held []string) {
+		for _, scope := range held {
+			if _, ok := seen[scope]; ok || scope == "" {
+				continue
+			}
+			seen[scope] = struct{}{}
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var cursor *string
+	for {
+		resp, err := p.client.ListServiceAccounts(ctx, cursor)
+		if skipWithoutPermission(ctx, err, permissionResourceType, "read:service_accounts") {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to list service accounts for permissions: %w", err)
+		}
+
+		for _, serviceAccount := range resp.Nodes {
+			addScopes(serviceAccount.Scopes)
+		}
+
+		if !resp.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &resp.PageInfo.EndCursor
+	}
+
+	// userRolesV2 returns every role in a single call
+	roles, err := p.client.ListUserRoles(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to list roles for permissions: %w", err)
+	}
+	for _, role := range roles.Nodes {
+		addScopes(role.Scopes)
+	}
+
+	slices.Sort(scopes)
+	for _, scope := range scopes {
+		permissionResource, err := newPermissionResource(scope)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create permission resource: %w", err)
+		}
+
+		permissions = append(permissions, permissionResource)
+	}
+
+	return permissions, nil, nil
+}
+
+// StaticEntitlements returns a static "assigned" entitlement template for all permissions.
+func (p *permissionBuilder) StaticEntitlements(ctx context.Context, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	var entitlements []*v2.Entitlement
+	entitlements = append(
+		entitlements,
+		ent.NewPermissionEntitlement(
+			nil,
+			"assigned",
+			ent.WithDisplayName("Permission Assigned"),
+			ent.WithDescription("Holds this Wiz API scope"),
+			ent.WithGrantableTo(serviceAccountResourceType, roleResourceType),
+		),
+	)
+
+	return entitlements, nil, nil
+}
+
+// Entitlements is required by ResourceSyncerV2 but we use StaticEntitlements instead.
+// This should not be called due to the SkipEntitlements annotation on the resource type.
+func (p *permissionBuilder) Entitlements(ctx context.Context, res *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// Grants returns grants for holders of this permission.
+// Permission grants are emitted from the service account and role resource types, see service_accounts.go and roles.go Grants().
+func (p *permissionBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// newPermissionResource creates a permission resource keyed by the scope name.
+func newPermissionResource(scope string) (*v2.Resource, error) {
+	return resource.NewResource(
+		scope,
+		permissionResourceType,
+		scope,
+		resource.WithDescription(fmt.Sprintf("Wiz API scope %s", scope)),
+	)
+}
+
+func newPermissionBuilder(client wiz.Client) *permissionBuilder {
+	return &permissionBuilder{client: client}
+}
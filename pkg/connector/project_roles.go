@@ -0,0 +1,255 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// projectRoleBuilder syncs project-scoped roles as scope bindings: one resource per project-scoped role and project,
+// so a grant on it says "role R scoped to project P".
+type projectRoleBuilder struct {
+	client         wiz.Client
+	fallbackRoleID string
+	// locks are the membership locks of projectBuilder, as granting a project role also changes the user's
+	// assigned projects.
+	locks *sync.Map
+}
+
+func (p *projectRoleBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return projectRoleResourceType
+}
+
+// List returns a scope binding for each project-scoped role within the parent project.
+// Project roles are children of projects, so nothing is returned at the top level.
+func (p *projectRoleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	if parentResourceID == nil || parentResourceID.GetResourceType() != projectResourceType.Id {
+		return nil, nil, nil
+	}
+
+	roles, err := p.projectScopedRoles(ctx, attr.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var projectRoles []*v2.Resource
+	for _, role := range roles {
+		projectRoleResource, err := newProjectRoleResource(role.ID, role.Name, parentResourceID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create project role resource: %w", err)
+		}
+
+		projectRoles = append(projectRoles, projectRoleResource)
+	}
+
+	return projectRoles, nil, nil
+}
+
+// projectRolesSessionKey holds the project-scoped roles in the session store of the running sync.
+const projectRolesSessionKey = "project-scoped-roles"
+
+// projectScopedRoles returns the project-scoped roles. They are listed once per sync and kept in the session store,
+// rather than listed again for every project.
+func (p *projectRoleBuilder) projectScopedRoles(ctx context.Context, store sessions.SessionStore) ([]wiz.UserRole, error) {
+	if store != nil {
+		roles, found, err := session.GetJSON[[]wiz.UserRole](ctx, store, projectRolesSessionKey)
+		if err != nil {
+			return nil, fmt.Errorf("wiz-connector: failed to read project-scoped roles: %w", err)
+		}
+		if found {
+			return roles, nil
+		}
+	}
+
+	// userRolesV2 returns every role in a single call
+	resp, err := p.client.ListUserRoles(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to list roles: %w", err)
+	}
+
+	roles := make([]wiz.UserRole, 0)
+	for _, role := range resp.Nodes {
+		if role.IsProjectScoped {
+			roles = append(roles, role)
+		}
+	}
+
+	if store != nil {
+		if err := session.SetJSON(ctx, store, projectRolesSessionKey, roles); err != nil {
+			return nil, fmt.Errorf("wiz-connector: failed to save project-scoped roles: %w", err)
+		}
+	}
+
+	return roles, nil
+}
+
+// StaticEntitlements returns a static "member" entitlement template for all project roles.
+func (p *projectRoleBuilder) StaticEntitlements(ctx context.Context, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	var entitlements []*v2.Entitlement
+	entitlements = append(
+		entitlements,
+		ent.NewAssignmentEntitlement(
+			nil,
+			"member",
+			ent.WithDisplayName("Project Role Member"),
+			ent.WithDescription("Holds a project-scoped Wiz role on the project. Granting it replaces the user's role and assigns them to the project"),
+			ent.WithGrantableTo(userResourceType),
+		),
+	)
+
+	return entitlements, nil, nil
+}
+
+// Entitlements is required by ResourceSyncerV2 but we use StaticEntitlements instead.
+// This should not be called due to the SkipEntitlements annotation on the resource type.
+func (p *projectRoleBuilder) Entitlements(ctx context.Context, res *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// Grants returns grants for users holding this role on the project.
+// Scoped role grants are emitted from the user resource type, see users.go Grants().
+func (p *projectRoleBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// Grant gives a user a project-scoped role on the project of the binding.
+// Wiz users hold exactly one role, so this replaces the user's role and adds the project to their assigned projects.
+func (p *projectRoleBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if principal.GetId().GetResourceType() != userResourceType.Id {
+		return nil, nil, status.Error(codes.FailedPrecondition, "wiz-connector: only users can be granted project roles")
+	}
+
+	projectID, roleID, err := parseProjectRoleID(entitlement.GetResource().GetId().GetResource())
+	if err != nil {
+		return nil, nil, err
+	}
+	email := principal.GetId().GetResource()
+
+	unlock := p.lock(email)
+	defer unlock()
+
+	user, err := p.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	newGrant := grant.NewGrant(entitlement.GetResource(), "member", principal.GetId())
+	if user.EffectiveRole.ID == roleID && slices.Contains(assignedProjectIDs(user), projectID) {
+		return []*v2.Grant{newGrant}, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	if err := setUserRole(ctx, p.client, user, roleID, []string{projectID}); err != nil {
+		return nil, nil, err
+	}
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+// Revoke removes the project from the assigned projects of a user holding the role. A project-scoped role needs a
+// project, so revoking it on the user's last project also reverts the user to the configured fallback role.
+func (p *projectRoleBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	if g.GetPrincipal().GetId().GetResourceType() != userResourceType.Id {
+		return nil, status.Error(codes.FailedPrecondition, "wiz-connector: only project roles of users can be revoked")
+	}
+
+	projectID, roleID, err := parseProjectRoleID(g.GetEntitlement().GetResource().GetId().GetResource())
+	if err != nil {
+		return nil, err
+	}
+	email := g.GetPrincipal().GetId().GetResource()
+
+	unlock := p.lock(email)
+	defer unlock()
+
+	user, err := p.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	projectIDs := assignedProjectIDs(user)
+	if user.EffectiveRole.ID != roleID || !slices.Contains(projectIDs, projectID) {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	remaining := slices.DeleteFunc(projectIDs, func(id string) bool { return id == projectID })
+	patch := wiz.UpdateUserPatch{AssignedProjectIDs: &remaining}
+	if len(remaining) == 0 {
+		if p.fallbackRoleID == "" {
+			return nil, status.Errorf(codes.FailedPrecondition, "wiz-connector: revoking role %s on the last project of user %s requires wiz-fallback-role-id to be configured", roleID, email)
+		}
+		fallback, err := p.client.GetRole(ctx, p.fallbackRoleID)
+		if err != nil {
+			return nil, fmt.Errorf("wiz-connector: failed to get role %s: %w", p.fallbackRoleID, err)
+		}
+		if fallback.IsProjectScoped {
+			return nil, status.Errorf(codes.FailedPrecondition, "wiz-connector: fallback role %s is project-scoped and cannot replace role %s on the last project of user %s", fallback.ID, roleID, email)
+		}
+		patch.Role = &fallback.ID
+	}
+
+	if err := p.client.UpdateUser(ctx, user.ID, patch); err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to revoke role %s on project %s from user %s: %w", roleID, projectID, email, err)
+	}
+
+	return nil, nil
+}
+
+// lock serializes changes to the user's role and assigned projects with the member updates of projectBuilder.
+func (p *projectRoleBuilder) lock(email string) func() {
+	mu, _ := p.locks.LoadOrStore(email, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// projectRoleID returns the resource ID of the binding of roleID to projectID.
+func projectRoleID(projectID, roleID string) string {
+	return fmt.Sprintf("%s:%s", projectID, roleID)
+}
+
+// parseProjectRoleID splits a project role resource ID into the project and role IDs, see projectRoleID.
+// Wiz project IDs never contain a colon, so the ID is split on the first one.
+func parseProjectRoleID(id string) (string, string, error) {
+	projectID, roleID, ok := strings.Cut(id, ":")
+	if !ok || projectID == "" || roleID == "" {
+		return "", "", status.Errorf(codes.InvalidArgument, "wiz-connector: invalid project role ID %q", id)
+	}
+	return projectID, roleID, nil
+}
+
+// newProjectRoleResource creates the scope binding of a role to a project.
+// The name is only used for display and may be empty when the resource is built for a grant.
+func newProjectRoleResource(roleID, roleName string, projectResourceID *v2.ResourceId) (*v2.Resource, error) {
+	projectID := projectResourceID.GetResource()
+
+	return resource.NewScopeBindingResource(
+		roleName,
+		projectRoleResourceType,
+		projectRoleID(projectID, roleID),
+		[]resource.ScopeBindingTraitOption{
+			resource.WithRoleScopeRoleId(&v2.ResourceId{ResourceType: roleResourceType.Id, Resource: roleID}),
+			resource.WithRoleScopeResourceId(projectResourceID),
+		},
+		resource.WithParentResourceID(projectResourceID),
+	)
+}
+
+func newProjectRoleBuilder(client wiz.Client, fallbackRoleID string, locks *sync.Map) *projectRoleBuilder {
+	return &projectRoleBuilder{
+		client:         client,
+		fallbackRoleID: fallbackRoleID,
+		locks:          locks,
+	}
+}
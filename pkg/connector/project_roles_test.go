@@ -0,0 +1,239 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProjectScopedRoleGrants(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.roles = []wiz.UserRole{
+		{ID: "GLOBAL_READER", Name: "Global Reader"},
+		{ID: "PROJECT_ADMIN", Name: "Project Admin", IsProjectScoped: true},
+	}
+
+	project := &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "project-1"}
+	projectRoles, _, err := newProjectRoleBuilder(client, "", &sync.Map{}).List(ctx, project, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, projectRoles, 1) {
+		return
+	}
+	assert.Equal(t, "project-1:PROJECT_ADMIN", projectRoles[0].GetId().GetResource())
+	assert.Equal(t, "project-1", projectRoles[0].GetParentResourceId().GetResource())
+
+	binding, err := resource.GetScopeBindingTrait(projectRoles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "PROJECT_ADMIN", binding.GetRoleId().GetResource())
+	assert.Equal(t, "project-1", binding.GetScopeResourceId().GetResource())
+
+	topLevel, _, err := newProjectRoleBuilder(client, "", &sync.Map{}).List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, topLevel)
+
+	t.Run("roles are listed once per sync", func(t *testing.T) {
+		calls := client.callCount("ListUserRoles")
+		builder := newProjectRoleBuilder(client, "", &sync.Map{})
+		store := newMemorySessionStore()
+		for _, projectID := range []string{"project-1", "project-2", "project-3"} {
+			parent := &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: projectID}
+			projectRoles, _, err := builder.List(ctx, parent, resource.SyncOpAttrs{Session: store})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if assert.Len(t, projectRoles, 1) {
+				assert.Equal(t, projectID+":PROJECT_ADMIN", projectRoles[0].GetId().GetResource())
+			}
+		}
+		assert.Equal(t, calls+1, client.callCount("ListUserRoles"))
+	})
+
+	tests := []struct {
+		name       string
+		role       wiz.UserRoleRef
+		wantGrants []string
+	}{
+		{
+			name: "project-scoped role is granted on each project",
+			role: wiz.UserRoleRef{ID: "PROJECT_ADMIN", IsProjectScoped: true},
+			wantGrants: []string{
+				"role:PROJECT_ADMIN:member",
+				"project:project-1:member",
+				"project:project-2:member",
+				"project-role:project-1:PROJECT_ADMIN:member",
+				"project-role:project-2:PROJECT_ADMIN:member",
+			},
+		},
+		{
+			name: "global role is not scoped",
+			role: wiz.UserRoleRef{ID: "GLOBAL_READER"},
+			wantGrants: []string{
+				"role:GLOBAL_READER:member",
+				"project:project-1:member",
+				"project:project-2:member",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userResource, err := newUserResource(&wiz.User{
+				Email:         "dana@example.com",
+				EffectiveRole: tt.role,
+				EffectiveAssignedProjects: []wiz.ProjectRef{
+					{ID: "project-1", Name: "Payments"},
+					{ID: "project-2", Name: "Data Platform"},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			grants, _, err := newUserBuilder(client, false).Grants(ctx, userResource, resource.SyncOpAttrs{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var entitlementIDs []string
+			for _, g := range grants {
+				entitlementIDs = append(entitlementIDs, g.GetEntitlement().GetId())
+			}
+			assert.Equal(t, tt.wantGrants, entitlementIDs)
+		})
+	}
+}
+
+func TestProjectRoleGrantAndRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		revoke   bool
+		fallback string
+		// userRoleID and userProjects describe the user before the grant or revoke.
+		userRoleID   string
+		userProjects []string
+		wantCode     codes.Code
+		wantAnno     proto.Message
+		wantRoleID   string
+		wantProjects []string
+	}{
+		{
+			name:         "grant sets the role and assigns the project",
+			userRoleID:   "GLOBAL_READER",
+			userProjects: []string{"project-2"},
+			wantRoleID:   "PROJECT_ADMIN",
+			wantProjects: []string{"project-2", "project-1"},
+		},
+		{
+			name:         "grant of the role on another project",
+			userRoleID:   "PROJECT_ADMIN",
+			userProjects: []string{"project-2"},
+			wantRoleID:   "PROJECT_ADMIN",
+			wantProjects: []string{"project-2", "project-1"},
+		},
+		{
+			name:         "grant of a role the user holds on the project",
+			userRoleID:   "PROJECT_ADMIN",
+			userProjects: []string{"project-1"},
+			wantAnno:     &v2.GrantAlreadyExists{},
+			wantRoleID:   "PROJECT_ADMIN",
+			wantProjects: []string{"project-1"},
+		},
+		{
+			name:         "revoke removes the project",
+			revoke:       true,
+			userRoleID:   "PROJECT_ADMIN",
+			userProjects: []string{"project-1", "project-2"},
+			wantRoleID:   "PROJECT_ADMIN",
+			wantProjects: []string{"project-2"},
+		},
+		{
+			name:         "revoke on the last project reverts to the fallback role",
+			revoke:       true,
+			fallback:     "GLOBAL_READER",
+			userRoleID:   "PROJECT_ADMIN",
+			userProjects: []string{"project-1"},
+			wantRoleID:   "GLOBAL_READER",
+			wantProjects: []string{},
+		},
+		{
+			name:         "revoke on the last project without a fallback role",
+			revoke:       true,
+			userRoleID:   "PROJECT_ADMIN",
+			userProjects: []string{"project-1"},
+			wantCode:     codes.FailedPrecondition,
+			wantRoleID:   "PROJECT_ADMIN",
+			wantProjects: []string{"project-1"},
+		},
+		{
+			name:         "revoke of a role the user does not hold",
+			revoke:       true,
+			fallback:     "GLOBAL_READER",
+			userRoleID:   "GLOBAL_READER",
+			userProjects: []string{"project-1"},
+			wantAnno:     &v2.GrantAlreadyRevoked{},
+			wantRoleID:   "GLOBAL_READER",
+			wantProjects: []string{"project-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient()
+			client.roles = []wiz.UserRole{
+				{ID: "GLOBAL_READER", Name: "Global Reader"},
+				{ID: "PROJECT_ADMIN", Name: "Project Admin", IsProjectScoped: true},
+			}
+			user := wiz.User{ID: "u1", Email: "alice@example.com", EffectiveRole: wiz.UserRoleRef{ID: tt.userRoleID}}
+			for _, projectID := range tt.userProjects {
+				user.AssignedProjects = append(user.AssignedProjects, wiz.ProjectRef{ID: projectID})
+			}
+			client.users = []wiz.User{user}
+			builder := newProjectRoleBuilder(client, tt.fallback, &sync.Map{})
+
+			projectRole, err := newProjectRoleResource("PROJECT_ADMIN", "Project Admin", &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "project-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "alice@example.com"}}
+
+			var annos annotations.Annotations
+			if tt.revoke {
+				annos, err = builder.Revoke(ctx, grant.NewGrant(projectRole, "member", principal.GetId()))
+			} else {
+				_, annos, err = builder.Grant(ctx, principal, ent.NewAssignmentEntitlement(projectRole, "member"))
+			}
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantAnno != nil {
+				assert.True(t, annos.Contains(tt.wantAnno))
+			} else {
+				assert.Empty(t, annos)
+			}
+			assert.Equal(t, tt.wantRoleID, client.users[0].EffectiveRole.ID)
+			assert.Equal(t, tt.wantProjects, assignedProjectIDs(&client.users[0]))
+			if tt.wantCode != codes.OK || tt.wantAnno != nil {
+				assert.Zero(t, client.callCount("UpdateUser"))
+			}
+		})
+	}
+}
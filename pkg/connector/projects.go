@@ -3,16 +3,33 @@ package connector
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// maxUpdateAttempts bounds how many times a read-modify-write update is retried after losing a race with a concurrent writer.
+const maxUpdateAttempts = 5
+
 type projectBuilder struct {
 	client wiz.Client
+	// expandProjectRoles adds an entitlement per project-scoped role, see StaticEntitlements.
+	expandProjectRoles bool
+	// locks serializes membership updates made by this connector process, keyed by project ID or user email.
+	// Other processes and Wiz users are only guarded against by the checks in updateMembership.
+	locks sync.Map
 }
 
 func (p *projectBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -36,13 +53,12 @@ func (p *projectBuilder) List(ctx context.Context, parentResourceID *v2.Resource
 	}
 
 	for _, project := range resp.Nodes {
-		projectResource, err := resource.NewGroupResource(
-			project.Name,
-			projectResourceType,
-			project.ID,
-			[]resource.GroupTraitOption{},
-			resource.WithDescription(project.Description),
-		)
+		// Archived projects are read-only in Wiz and no longer grant access
+		if project.Archived {
+			continue
+		}
+
+		projectResource, err := newProjectResource(&project)
 		if err != nil {
 			return nil, nil, fmt.Errorf("wiz-connector: failed to create project resource: %w", err)
 		}
@@ -59,7 +75,27 @@ func (p *projectBuilder) List(ctx context.Context, parentResourceID *v2.Resource
 	return projects, syncResults, nil
 }
 
+// Get returns a single project by ID, e.g. to refresh it after provisioning without a full sync.
+// Archived projects are reported as not found, as they are skipped by List.
+func (p *projectBuilder) Get(ctx context.Context, resourceId *v2.ResourceId, parentResourceId *v2.ResourceId) (*v2.Resource, annotations.Annotations, error) {
+	project, err := p.client.GetProject(ctx, resourceId.GetResource())
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get project %s: %w", resourceId.GetResource(), err)
+	}
+	if project.Archived {
+		return nil, nil, status.Errorf(codes.NotFound, "wiz-connector: project %s is archived", project.ID)
+	}
+
+	projectResource, err := newProjectResource(project)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to create project resource: %w", err)
+	}
+
+	return projectResource, nil, nil
+}
+
 // StaticEntitlements returns static "owner", "champion", and "member" entitlements for all projects.
+// When project roles are expanded, each project-scoped role also gets an entitlement whose slug is the role ID.
 // This is called once per resource type, not per resource.
 func (p *projectBuilder) StaticEntitlements(ctx context.Context, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
 	var entitlements []*v2.Entitlement
@@ -89,6 +125,33 @@ func (p *projectBuilder) StaticEntitlements(ctx context.Context, _ resource.Sync
 		),
 	)
 
+	if !p.expandProjectRoles {
+		return entitlements, nil, nil
+	}
+
+	// userRolesV2 returns every role in a single call
+	resp, err := p.client.ListUserRoles(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to list roles for project entitlements: %w", err)
+	}
+	for _, role := range resp.Nodes {
+		if !role.IsProjectScoped {
+			continue
+		}
+		entitlements = append(
+			entitlements,
+			ent.NewPermissionEntitlement(
+				nil,
+				role.ID,
+				ent.WithDisplayName(fmt.Sprintf("Project %s", role.Name)),
+				ent.WithDescription(fmt.Sprintf("Holds the %s role on a Wiz project", role.Name)),
+				ent.WithGrantableTo(userResourceType),
+				// Read-only: the role is granted through the role and project member entitlements
+				ent.WithAnnotation(&v2.EntitlementImmutable{}),
+			),
+		)
+	}
+
 	return entitlements, nil, nil
 }
 
@@ -98,82 +161,568 @@ func (p *projectBuilder) Entitlements(ctx context.Context, res *v2.Resource, _ r
 	return nil, nil, nil
 }
 
-// Grants returns grants for users who are members of this project.
-// Wiz projects have projectOwners and securityChampions.
+// Grants returns grants for the owners and security champions of this project.
+// Owner and champion emails are read from the project profile populated during List(), so no requests are made.
+// Resources without that profile data (e.g. not produced by List) fall back to a single GetProject request.
+// Member grants are emitted from the user resource type, see users.go Grants().
 func (p *projectBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
 	var grants []*v2.Grant
 
-	// Get the page token from the sync attributes
-	var cursor *string
-	if attr.PageToken.Token != "" {
-		cursor = &attr.PageToken.Token
+	ownerEmails, championEmails, ok := projectMembersFromProfile(res)
+	if !ok {
+		project, err := p.client.GetProject(ctx, res.Id.Resource)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to get project for grants: %w", err)
+		}
+		ownerEmails, championEmails = projectMemberEmails(project)
 	}
 
-	projectID := res.Id.Resource
+	// Use email as the user ID to match how we sync users (email is consistent across endpoints)
+	for _, email := range ownerEmails {
+		userResource, err := resource.NewResourceID(userResourceType, email)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create user resource ID for owner: %w", err)
+		}
+		grants = append(grants, grant.NewGrant(res, "owner", userResource))
+	}
 
-	// Fetch one page of projects
-	resp, err := p.client.ListProjects(ctx, cursor)
+	for _, email := range championEmails {
+		userResource, err := resource.NewResourceID(userResourceType, email)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create user resource ID for champion: %w", err)
+		}
+		grants = append(grants, grant.NewGrant(res, "champion", userResource))
+	}
+
+	return grants, nil, nil
+}
+
+// newProjectResource creates a project resource, storing owner and champion emails in the profile for use in Grants().
+func newProjectResource(project *wiz.Project) (*v2.Resource, error) {
+	ownerEmails, championEmails := projectMemberEmails(project)
+
+	profile := map[string]interface{}{
+		"owner_emails":    toInterfaceSlice(ownerEmails),
+		"champion_emails": toInterfaceSlice(championEmails),
+	}
+	if project.BusinessUnit != "" {
+		profile["business_unit"] = project.BusinessUnit
+	}
+
+	return resource.NewGroupResource(
+		project.Name,
+		projectResourceType,
+		project.ID,
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+		resource.WithDescription(project.Description),
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: projectRoleResourceType.Id}),
+	)
+}
+
+// projectMemberEmails returns the emails of a project's owners and security champions, skipping entries without an email.
+func projectMemberEmails(project *wiz.Project) ([]string, []string) {
+	ownerEmails := make([]string, 0, len(project.ProjectOwners))
+	for _, owner := range project.ProjectOwners {
+		if owner.Email != "" {
+			ownerEmails = append(ownerEmails, owner.Email)
+		}
+	}
+
+	championEmails := make([]string, 0, len(project.SecurityChampions))
+	for _, champion := range project.SecurityChampions {
+		if champion.Email != "" {
+			championEmails = append(championEmails, champion.Email)
+		}
+	}
+
+	return ownerEmails, championEmails
+}
+
+// projectMembersFromProfile reads owner and champion emails from the project profile.
+// It returns false if the profile was not populated by List().
+func projectMembersFromProfile(res *v2.Resource) ([]string, []string, bool) {
+	groupTrait, err := resource.GetGroupTrait(res)
 	if err != nil {
-		return nil, nil, fmt.Errorf("wiz-connector: failed to list projects for grants: %w", err)
+		return nil, nil, false
 	}
 
-	// Find the specific project we're getting grants for
-	for _, project := range resp.Nodes {
-		if project.ID != projectID {
-			continue
+	fields := groupTrait.GetProfile().GetFields()
+	owners, ok := fields["owner_emails"]
+	if !ok {
+		return nil, nil, false
+	}
+	champions, ok := fields["champion_emails"]
+	if !ok {
+		return nil, nil, false
+	}
+
+	return profileStrings(owners), profileStrings(champions), true
+}
+
+// profileStrings returns the non-empty string values of a profile list value.
+func profileStrings(value *structpb.Value) []string {
+	var out []string
+	for _, v := range value.GetListValue().GetValues() {
+		if str := v.GetStringValue(); str != "" {
+			out = append(out, str)
 		}
+	}
+	return out
+}
 
-		// Create grants for project owners with "owner" entitlement
-		// Use email as the user ID to match how we sync users (email is consistent across endpoints)
-		for _, owner := range project.ProjectOwners {
-			if owner.Email == "" {
-				continue // Skip if no email
-			}
-			userResource, err := resource.NewResourceID(userResourceType, owner.Email)
+// activeProjectIDs returns the IDs of the referenced projects that are not archived.
+// Archived projects are skipped by List, so grants on them would point at resources missing from the sync.
+func activeProjectIDs(projects []wiz.ProjectRef) []string {
+	ids := make([]string, 0, len(projects))
+	for _, project := range projects {
+		if !project.Archived {
+			ids = append(ids, project.ID)
+		}
+	}
+	return ids
+}
+
+// toInterfaceSlice converts a string slice to the []interface{} form required by resource profiles.
+func toInterfaceSlice(values []string) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Grant adds a user to a project as an owner, security champion, or member.
+// Owners and champions are stored on the project; members are stored as the user's assigned projects.
+func (p *projectBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if principal.GetId().GetResourceType() != userResourceType.Id {
+		return nil, nil, status.Error(codes.FailedPrecondition, "wiz-connector: only users can be granted project entitlements")
+	}
+
+	projectID := entitlement.GetResource().GetId().GetResource()
+	email := principal.GetId().GetResource()
+	slug := entitlementSlug(entitlement)
+
+	changed, err := p.updateProjectMembership(ctx, projectID, email, slug, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grant.NewGrant(entitlement.GetResource(), slug, principal.GetId())
+	if !changed {
+		return []*v2.Grant{newGrant}, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+// Revoke removes a user from a project's owners, security champions, or members.
+// Grants to SAML group mappings come from the identity provider configuration and cannot be revoked here.
+func (p *projectBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	if g.GetPrincipal().GetId().GetResourceType() != userResourceType.Id {
+		return nil, status.Error(codes.FailedPrecondition, "wiz-connector: only project entitlements of users can be revoked")
+	}
+
+	projectID := g.GetEntitlement().GetResource().GetId().GetResource()
+	email := g.GetPrincipal().GetId().GetResource()
+	slug := entitlementSlug(g.GetEntitlement())
+
+	changed, err := p.updateProjectMembership(ctx, projectID, email, slug, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	return nil, nil
+}
+
+// updateProjectMembership adds or removes a user from the list backing a project entitlement.
+// Owner and champion lists hold user IDs on the project, while the member list holds project IDs on the user.
+// It reports whether a change was made.
+func (p *projectBuilder) updateProjectMembership(ctx context.Context, projectID, email, slug string, add bool) (bool, error) {
+	user, err := p.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return false, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	var (
+		lockKey  string
+		memberID string
+		read     func(ctx context.Context) ([]string, error)
+		write    func(ctx context.Context, ids []string) error
+	)
+
+	switch slug {
+	case "owner", "champion":
+		lockKey = projectID
+		memberID = user.ID
+		read = func(ctx context.Context) ([]string, error) {
+			project, err := p.client.GetProject(ctx, projectID)
 			if err != nil {
-				return nil, nil, fmt.Errorf("wiz-connector: failed to create user resource ID for owner: %w", err)
+				return nil, fmt.Errorf("wiz-connector: failed to get project %s: %w", projectID, err)
 			}
-
-			g := grant.NewGrant(
-				res,
-				"owner",
-				userResource,
-			)
-			grants = append(grants, g)
+			ids := make([]string, 0)
+			if slug == "owner" {
+				for _, owner := range project.ProjectOwners {
+					ids = append(ids, owner.ID)
+				}
+			} else {
+				for _, champion := range project.SecurityChampions {
+					ids = append(ids, champion.ID)
+				}
+			}
+			return ids, nil
 		}
-
-		// Create grants for security champions with "champion" entitlement
-		for _, champion := range project.SecurityChampions {
-			if champion.Email == "" {
-				continue // Skip if no email
+		write = func(ctx context.Context, ids []string) error {
+			patch := wiz.UpdateProjectPatch{}
+			if slug == "owner" {
+				patch.ProjectOwners = &ids
+			} else {
+				patch.SecurityChampions = &ids
+			}
+			if err := p.client.UpdateProject(ctx, projectID, patch); err != nil {
+				return fmt.Errorf("wiz-connector: failed to update project %s: %w", projectID, err)
 			}
-			userResource, err := resource.NewResourceID(userResourceType, champion.Email)
+			return nil
+		}
+
+	case "member":
+		lockKey = email
+		memberID = projectID
+		read = func(ctx context.Context) ([]string, error) {
+			current, err := p.client.GetUserByEmail(ctx, email)
 			if err != nil {
-				return nil, nil, fmt.Errorf("wiz-connector: failed to create user resource ID for champion: %w", err)
+				return nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
 			}
+			return assignedProjectIDs(current), nil
+		}
+		write = func(ctx context.Context, ids []string) error {
+			if err := p.client.UpdateUser(ctx, user.ID, wiz.UpdateUserPatch{AssignedProjectIDs: &ids}); err != nil {
+				return fmt.Errorf("wiz-connector: failed to update projects for user %s: %w", email, err)
+			}
+			return nil
+		}
+
+	default:
+		return false, status.Errorf(codes.InvalidArgument, "wiz-connector: unsupported project entitlement %q", slug)
+	}
 
-			g := grant.NewGrant(
-				res,
-				"champion",
-				userResource,
-			)
-			grants = append(grants, g)
+	modify := func(ids []string) ([]string, bool) {
+		if slices.Contains(ids, memberID) == add {
+			return ids, false
+		}
+		if add {
+			return append(slices.Clone(ids), memberID), true
 		}
+		return slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == memberID }), true
+	}
 
-		// Found the project, no need to continue
-		break
+	mu, _ := p.locks.LoadOrStore(lockKey, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	return updateMembership(ctx, read, write, modify)
+}
+
+// updateMembership performs a best-effort read-modify-write of a membership list.
+// Wiz has no conditional updates, so the list is re-read immediately before writing and again afterwards:
+//   - if the list moved between the first read and the one before writing, the update starts over from a fresh read
+//   - if the list read after writing is not the one written, another writer changed it after us; the update starts
+//     over from that list, which keeps their change and re-applies ours if they dropped it
+//
+// A write by another process landing between the last read and our write is overwritten without being detected.
+// This narrows the window for lost updates but is not concurrency control; updates made through this connector
+// process are fully serialized by the locks of projectBuilder.
+func updateMembership(
+	ctx context.Context,
+	read func(ctx context.Context) ([]string, error),
+	write func(ctx context.Context, ids []string) error,
+	modify func(ids []string) ([]string, bool),
+) (bool, error) {
+	wrote := false
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		current, err := read(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		desired, changed := modify(current)
+		if !changed {
+			// Either nothing was needed, or a write of a previous attempt survived a concurrent change.
+			return wrote, nil
+		}
+
+		// Start over if the list moved since we read it.
+		latest, err := read(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !sameMembers(current, latest) {
+			continue
+		}
+
+		if err := write(ctx, desired); err != nil {
+			return false, err
+		}
+		wrote = true
+
+		after, err := read(ctx)
+		if err != nil {
+			return false, err
+		}
+		if sameMembers(after, desired) {
+			return true, nil
+		}
 	}
 
-	// Prepare the sync results with next page token if there are more pages
-	// Note: This will continue paginating through all projects until we find the one we need
-	syncResults := &resource.SyncOpResults{}
-	if resp.PageInfo.HasNextPage {
-		syncResults.NextPageToken = resp.PageInfo.EndCursor
+	return false, status.Errorf(codes.Aborted, "wiz-connector: membership update lost to concurrent modifications after %d attempts", maxUpdateAttempts)
+}
+
+// entitlementSlug returns the entitlement's slug, falling back to the last segment of its ID.
+func entitlementSlug(e *v2.Entitlement) string {
+	if slug := e.GetSlug(); slug != "" {
+		return slug
 	}
+	id := e.GetId()
+	return id[strings.LastIndex(id, ":")+1:]
+}
+
+// sameMembers reports whether two ID lists contain the same members, ignoring order.
+func sameMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// projectSpec describes a project to create. Owners and champions are identified by email, like synced users.
+type projectSpec struct {
+	name            string
+	description     string
+	businessUnit    string
+	businessImpact  string
+	environment     string
+	ownerEmails     []string
+	championEmails  []string
+	cloudAccountIDs []string
+}
 
-	return grants, syncResults, nil
+// Create creates a Wiz project from the resource's display name and description and the fields of its group profile:
+// owner_emails, champion_emails, cloud_account_ids, business_unit, business_impact and environment.
+func (p *projectBuilder) Create(ctx context.Context, res *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	var profile *structpb.Struct
+	if groupTrait, err := resource.GetGroupTrait(res); err == nil {
+		profile = groupTrait.GetProfile()
+	}
+	fields := profile.GetFields()
+
+	projectResource, err := p.createProject(ctx, projectSpec{
+		name:            res.GetDisplayName(),
+		description:     res.GetDescription(),
+		businessUnit:    fields["business_unit"].GetStringValue(),
+		businessImpact:  fields["business_impact"].GetStringValue(),
+		environment:     fields["environment"].GetStringValue(),
+		ownerEmails:     profileStrings(fields["owner_emails"]),
+		championEmails:  profileStrings(fields["champion_emails"]),
+		cloudAccountIDs: profileStrings(fields["cloud_account_ids"]),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return projectResource, nil, nil
 }
 
-func newProjectBuilder(client wiz.Client) *projectBuilder {
-	return &projectBuilder{client: client}
+// Delete archives the Wiz project. Wiz keeps archived projects read-only rather than deleting them.
+func (p *projectBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId, parentResourceID *v2.ResourceId) (annotations.Annotations, error) {
+	projectID := resourceId.GetResource()
+
+	project, err := p.client.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to get project %s: %w", projectID, err)
+	}
+	if project.Archived {
+		return nil, nil
+	}
+
+	archived := true
+	if err := p.client.UpdateProject(ctx, projectID, wiz.UpdateProjectPatch{Archived: &archived}); err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to archive project %s: %w", projectID, err)
+	}
+
+	return nil, nil
+}
+
+// ResourceActions registers the create_project action, whose schema describes the project creation form.
+func (p *projectBuilder) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	return registry.Register(ctx, createProjectActionSchema, p.createProjectAction)
+}
+
+var createProjectActionSchema = v2.BatonActionSchema_builder{
+	Name:        "create_project",
+	DisplayName: "Create Project",
+	Description: "Create a Wiz project with its owners, security champions, and linked cloud accounts",
+	Arguments: []*config.Field{
+		config.Field_builder{
+			Name:        "name",
+			DisplayName: "Name",
+			Description: "Name of the project",
+			IsRequired:  true,
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "description",
+			DisplayName: "Description",
+			Description: "Description of the project",
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:             "owner_emails",
+			DisplayName:      "Owners",
+			Description:      "Emails of the Wiz users who own the project",
+			StringSliceField: &config.StringSliceField{},
+		}.Build(),
+		config.Field_builder{
+			Name:             "champion_emails",
+			DisplayName:      "Security Champions",
+			Description:      "Emails of the Wiz users who are security champions for the project",
+			StringSliceField: &config.StringSliceField{},
+		}.Build(),
+		config.Field_builder{
+			Name:             "cloud_account_ids",
+			DisplayName:      "Cloud Accounts",
+			Description:      "IDs of the Wiz cloud accounts linked to the project",
+			StringSliceField: &config.StringSliceField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "environment",
+			DisplayName: "Environment",
+			Description: "Environment of the linked cloud accounts: PRODUCTION, STAGING, DEVELOPMENT, TESTING or OTHER. Defaults to PRODUCTION",
+			Placeholder: wiz.DefaultProjectEnvironment,
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "business_unit",
+			DisplayName: "Business Unit",
+			Description: "Business unit the project belongs to",
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "business_impact",
+			DisplayName: "Business Impact",
+			Description: "Risk profile business impact: LBI (low), MBI (medium) or HBI (high)",
+			StringField: &config.StringField{},
+		}.Build(),
+	},
+	ReturnTypes: []*config.Field{
+		config.Field_builder{Name: "success", BoolField: &config.BoolField{}}.Build(),
+		config.Field_builder{Name: "resource", ResourceField: &config.ResourceField{}}.Build(),
+	},
+	ActionType: []v2.ActionType{v2.ActionType_ACTION_TYPE_RESOURCE_CREATE},
+}.Build()
+
+// createProjectAction handles the create_project action.
+func (p *projectBuilder) createProjectAction(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	spec := projectSpec{}
+	spec.name, _ = actions.GetStringArg(args, "name")
+	spec.description, _ = actions.GetStringArg(args, "description")
+	spec.businessUnit, _ = actions.GetStringArg(args, "business_unit")
+	spec.businessImpact, _ = actions.GetStringArg(args, "business_impact")
+	spec.environment, _ = actions.GetStringArg(args, "environment")
+	spec.ownerEmails, _ = actions.GetStringSliceArg(args, "owner_emails")
+	spec.championEmails, _ = actions.GetStringSliceArg(args, "champion_emails")
+	spec.cloudAccountIDs, _ = actions.GetStringSliceArg(args, "cloud_account_ids")
+
+	projectResource, err := p.createProject(ctx, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resourceField, err := actions.NewResourceReturnField("resource", projectResource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to build action result: %w", err)
+	}
+
+	return actions.NewReturnValues(true, resourceField), nil, nil
+}
+
+// createProject validates spec, resolves owner and champion emails to Wiz user IDs, and creates the project.
+func (p *projectBuilder) createProject(ctx context.Context, spec projectSpec) (*v2.Resource, error) {
+	if spec.name == "" {
+		return nil, status.Error(codes.InvalidArgument, "wiz-connector: a name is required to create a project")
+	}
+	if spec.businessImpact != "" && !slices.Contains(wiz.ProjectBusinessImpacts, spec.businessImpact) {
+		return nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid business impact %q, expected one of %v", spec.businessImpact, wiz.ProjectBusinessImpacts)
+	}
+	if spec.environment == "" {
+		spec.environment = wiz.DefaultProjectEnvironment
+	}
+	if !slices.Contains(wiz.ProjectEnvironments, spec.environment) {
+		return nil, status.Errorf(codes.InvalidArgument, "wiz-connector: invalid environment %q, expected one of %v", spec.environment, wiz.ProjectEnvironments)
+	}
+
+	ownerIDs, err := p.userIDs(ctx, spec.ownerEmails)
+	if err != nil {
+		return nil, err
+	}
+	championIDs, err := p.userIDs(ctx, spec.championEmails)
+	if err != nil {
+		return nil, err
+	}
+
+	input := wiz.CreateProjectInput{
+		Name:              spec.name,
+		Description:       spec.description,
+		BusinessUnit:      spec.businessUnit,
+		ProjectOwners:     ownerIDs,
+		SecurityChampions: championIDs,
+	}
+	for _, cloudAccountID := range spec.cloudAccountIDs {
+		input.CloudAccountLinks = append(input.CloudAccountLinks, wiz.ProjectCloudAccountLink{
+			CloudAccount: cloudAccountID,
+			Environment:  spec.environment,
+		})
+	}
+	if spec.businessImpact != "" {
+		input.RiskProfile = &wiz.ProjectRiskProfile{BusinessImpact: spec.businessImpact}
+	}
+
+	project, err := p.client.CreateProject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to create project %s: %w", spec.name, err)
+	}
+
+	projectResource, err := newProjectResource(project)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to create project resource: %w", err)
+	}
+
+	return projectResource, nil
+}
+
+// userIDs resolves user emails to Wiz user IDs.
+func (p *projectBuilder) userIDs(ctx context.Context, emails []string) ([]string, error) {
+	ids := make([]string, 0, len(emails))
+	for _, email := range emails {
+		user, err := p.client.GetUserByEmail(ctx, email)
+		if err != nil {
+			return nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+func newProjectBuilder(client wiz.Client, expandProjectRoles bool) *projectBuilder {
+	return &projectBuilder{
+		client:             client,
+		expandProjectRoles: expandProjectRoles,
+	}
 }
@@ -0,0 +1,398 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProjectGrantsDoNotRelistProjects(t *testing.T) {
+	ctx := context.Background()
+
+	const projectCount = 250
+	client := newFakeClient()
+	for i := 0; i < projectCount; i++ {
+		client.projects = append(client.projects, wiz.Project{
+			ID:                fmt.Sprintf("project-%d", i),
+			Name:              fmt.Sprintf("Project %d", i),
+			ProjectOwners:     []wiz.ProjectOwner{{ID: "u1", Email: "owner@example.com"}},
+			SecurityChampions: []wiz.SecurityChampion{{ID: "u2", Email: "champion@example.com"}, {ID: "u3"}},
+		})
+	}
+	builder := newProjectBuilder(client, false)
+
+	var resources []*v2.Resource
+	token := ""
+	for {
+		page, results, err := builder.List(ctx, nil, resource.SyncOpAttrs{PageToken: pagination.Token{Token: token}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources = append(resources, page...)
+		if results.NextPageToken == "" {
+			break
+		}
+		token = results.NextPageToken
+	}
+	assert.Len(t, resources, projectCount)
+	listCalls := client.callCount("ListProjects")
+	assert.Equal(t, 3, listCalls)
+
+	for _, res := range resources {
+		grants, results, err := builder.Grants(ctx, res, resource.SyncOpAttrs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, results)
+		if !assert.Len(t, grants, 2) {
+			continue
+		}
+		assert.Equal(t, "project:"+res.GetId().GetResource()+":owner", grants[0].GetEntitlement().GetId())
+		assert.Equal(t, "owner@example.com", grants[0].GetPrincipal().GetId().GetResource())
+		assert.Equal(t, "project:"+res.GetId().GetResource()+":champion", grants[1].GetEntitlement().GetId())
+		assert.Equal(t, "champion@example.com", grants[1].GetPrincipal().GetId().GetResource())
+	}
+
+	// Grants must be served from the data captured during List.
+	assert.Equal(t, listCalls, client.callCount("ListProjects"))
+	assert.Zero(t, client.callCount("GetProject"))
+}
+
+func TestProjectGrantsFallBackToGetProject(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.projects = []wiz.Project{{
+		ID:            "project-1",
+		Name:          "Project 1",
+		ProjectOwners: []wiz.ProjectOwner{{ID: "u1", Email: "owner@example.com"}},
+	}}
+	builder := newProjectBuilder(client, false)
+
+	// A resource without the List profile, e.g. one referenced by ID only.
+	res, err := resource.NewGroupResource("Project 1", projectResourceType, "project-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grants, _, err := builder.Grants(ctx, res, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, grants, 1) {
+		assert.Equal(t, "owner@example.com", grants[0].GetPrincipal().GetId().GetResource())
+	}
+	assert.Equal(t, 1, client.callCount("GetProject"))
+	assert.Zero(t, client.callCount("ListProjects"))
+}
+
+func TestProjectMembershipGrantAndRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	members := map[string]func(client *fakeClient) []string{
+		"owner": func(client *fakeClient) []string {
+			var ids []string
+			for _, owner := range client.projects[0].ProjectOwners {
+				ids = append(ids, owner.ID)
+			}
+			return ids
+		},
+		"champion": func(client *fakeClient) []string {
+			var ids []string
+			for _, champion := range client.projects[0].SecurityChampions {
+				ids = append(ids, champion.ID)
+			}
+			return ids
+		},
+		"member": func(client *fakeClient) []string {
+			var ids []string
+			for _, project := range client.users[0].AssignedProjects {
+				ids = append(ids, project.ID)
+			}
+			return ids
+		},
+	}
+	want := map[string][]string{"owner": {"u1"}, "champion": {"u1"}, "member": {"project-1"}}
+
+	for _, slug := range []string{"owner", "champion", "member"} {
+		t.Run(slug, func(t *testing.T) {
+			client := newFakeClient()
+			client.users = []wiz.User{{ID: "u1", Email: "alice@example.com"}}
+			client.projects = []wiz.Project{{ID: "project-1", Name: "Payments"}}
+			builder := newProjectBuilder(client, false)
+
+			projectResource, err := resource.NewGroupResource("Payments", projectResourceType, "project-1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "alice@example.com"}}
+			entitlement := ent.NewAssignmentEntitlement(projectResource, slug)
+
+			grants, annos, err := builder.Grant(ctx, principal, entitlement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Empty(t, annos)
+			assert.Equal(t, want[slug], members[slug](client))
+
+			// Granting again leaves the list alone.
+			updates := client.callCount("UpdateProject") + client.callCount("UpdateUser")
+			_, annos, err = builder.Grant(ctx, principal, entitlement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, annos.Contains(&v2.GrantAlreadyExists{}))
+			assert.Equal(t, updates, client.callCount("UpdateProject")+client.callCount("UpdateUser"))
+
+			annos, err = builder.Revoke(ctx, grants[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Empty(t, annos)
+			assert.Empty(t, members[slug](client))
+
+			annos, err = builder.Revoke(ctx, grants[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, annos.Contains(&v2.GrantAlreadyRevoked{}))
+		})
+	}
+}
+
+func TestUpdateMembershipConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// concurrent is another writer's change to the list, applied before the given read of our update.
+		concurrent  map[int][]string
+		wantChanged bool
+		wantWrites  [][]string
+	}{
+		{
+			name:        "no concurrent change",
+			wantChanged: true,
+			wantWrites:  [][]string{{"a", "c"}},
+		},
+		{
+			name:        "list changed before writing",
+			concurrent:  map[int][]string{2: {"a", "b"}},
+			wantChanged: true,
+			wantWrites:  [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:        "write replaced by a stale copy",
+			concurrent:  map[int][]string{3: {"a", "b"}},
+			wantChanged: true,
+			wantWrites:  [][]string{{"a", "c"}, {"a", "b", "c"}},
+		},
+		{
+			name:        "list changed after writing",
+			concurrent:  map[int][]string{3: {"a", "c", "d"}},
+			wantChanged: true,
+			wantWrites:  [][]string{{"a", "c"}},
+		},
+		{
+			name:        "concurrent writer made the change first",
+			concurrent:  map[int][]string{2: {"a", "c"}},
+			wantChanged: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := []string{"a"}
+			reads := 0
+			var writes [][]string
+
+			read := func(ctx context.Context) ([]string, error) {
+				reads++
+				if ids, ok := tt.concurrent[reads]; ok {
+					list = ids
+				}
+				return list, nil
+			}
+			write := func(ctx context.Context, ids []string) error {
+				writes = append(writes, ids)
+				list = ids
+				return nil
+			}
+			modify := func(ids []string) ([]string, bool) {
+				if slices.Contains(ids, "c") {
+					return ids, false
+				}
+				return append(slices.Clone(ids), "c"), true
+			}
+
+			changed, err := updateMembership(ctx, read, write, modify)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.wantWrites, writes)
+			assert.Contains(t, list, "c")
+		})
+	}
+
+	t.Run("gives up after repeated conflicts", func(t *testing.T) {
+		reads := 0
+		read := func(ctx context.Context) ([]string, error) {
+			reads++
+			return []string{fmt.Sprintf("writer-%d", reads)}, nil
+		}
+		write := func(ctx context.Context, ids []string) error {
+			t.Fatal("unexpected write")
+			return nil
+		}
+		modify := func(ids []string) ([]string, bool) {
+			return append(slices.Clone(ids), "c"), true
+		}
+
+		_, err := updateMembership(ctx, read, write, modify)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+}
+
+func TestProjectCreateAndArchive(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.users = []wiz.User{
+		{ID: "user-1", Email: "alice@example.com"},
+		{ID: "user-2", Email: "bob@example.com"},
+	}
+	builder := newProjectBuilder(client, false)
+
+	projectResource, err := resource.NewGroupResource(
+		"Payments",
+		projectResourceType,
+		"",
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(map[string]interface{}{
+				"owner_emails":      []interface{}{"alice@example.com"},
+				"champion_emails":   []interface{}{"bob@example.com"},
+				"cloud_account_ids": []interface{}{"cloud-account-1"},
+				"business_impact":   "HBI",
+			}),
+		},
+		resource.WithDescription("Payment services"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, _, err := builder.Create(ctx, projectResource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Payments", created.GetDisplayName())
+	if assert.Len(t, client.projectInputs, 1) {
+		input := client.projectInputs[0]
+		assert.Equal(t, "Payment services", input.Description)
+		assert.Equal(t, []string{"user-1"}, input.ProjectOwners)
+		assert.Equal(t, []string{"user-2"}, input.SecurityChampions)
+		assert.Equal(t, []wiz.ProjectCloudAccountLink{{CloudAccount: "cloud-account-1", Environment: "PRODUCTION"}}, input.CloudAccountLinks)
+		assert.Equal(t, &wiz.ProjectRiskProfile{BusinessImpact: "HBI"}, input.RiskProfile)
+	}
+
+	t.Run("create project action", func(t *testing.T) {
+		args, err := structpb.NewStruct(map[string]interface{}{
+			"name":         "Data Platform",
+			"owner_emails": []interface{}{"bob@example.com"},
+			"environment":  "STAGING",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, _, err := builder.createProjectAction(ctx, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result.GetFields()["success"].GetBoolValue())
+		assert.Equal(t, "Data Platform", client.projects[1].Name)
+		assert.Equal(t, []string{"user-2"}, client.projectInputs[1].ProjectOwners)
+	})
+
+	t.Run("invalid input is refused", func(t *testing.T) {
+		creates := client.callCount("CreateProject")
+		for _, args := range []map[string]interface{}{
+			{"name": ""},
+			{"name": "Invalid", "business_impact": "EXTREME"},
+			{"name": "Invalid", "environment": "PROD"},
+		} {
+			structArgs, err := structpb.NewStruct(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = builder.createProjectAction(ctx, structArgs)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+
+		args, err := structpb.NewStruct(map[string]interface{}{"name": "Unknown Owner", "owner_emails": []interface{}{"nobody@example.com"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = builder.createProjectAction(ctx, args)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, creates, client.callCount("CreateProject"))
+	})
+
+	t.Run("delete archives the project", func(t *testing.T) {
+		if _, err := builder.Delete(ctx, created.GetId(), nil); err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, client.projects[0].Archived)
+
+		// Archiving an archived project is a no-op.
+		updates := client.callCount("UpdateProject")
+		if _, err := builder.Delete(ctx, created.GetId(), nil); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, updates, client.callCount("UpdateProject"))
+
+		projects, _, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assert.Len(t, projects, 1) {
+			assert.Equal(t, "Data Platform", projects[0].GetDisplayName())
+		}
+	})
+}
+
+func TestProjectGet(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	client.projects = []wiz.Project{
+		{ID: "project-1", Name: "Payments", ProjectOwners: []wiz.ProjectOwner{{ID: "u1", Email: "alice@example.com"}}},
+		{ID: "project-2", Name: "Legacy", Archived: true},
+	}
+	builder := newProjectBuilder(client, false)
+
+	res, _, err := builder.Get(ctx, &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "project-1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Payments", res.GetDisplayName())
+	owners, _, ok := projectMembersFromProfile(res)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"alice@example.com"}, owners)
+	assert.Equal(t, 0, client.callCount("ListProjects"))
+
+	// Archived projects are not synced, so they cannot be fetched either.
+	_, _, err = builder.Get(ctx, &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "project-2"}, nil)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
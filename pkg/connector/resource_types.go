@@ -1,8 +1,14 @@
 package connector
 
 import (
+	"context"
+
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // userResourceType represents Wiz users.
@@ -14,6 +20,7 @@ var userResourceType = &v2.ResourceType{
 		&v2.CapabilityPermissions{
 			Permissions: []*v2.CapabilityPermission{
 				{Permission: "read:users"},
+				{Permission: "write:users"}, // Required for creating and deleting users
 			},
 		},
 		&v2.SkipEntitlements{},
@@ -28,7 +35,8 @@ var roleResourceType = &v2.ResourceType{
 	Annotations: annotations.New(
 		&v2.CapabilityPermissions{
 			Permissions: []*v2.CapabilityPermission{
-				{Permission: "read:users"}, // Required for fetching user-to-role memberships
+				{Permission: "read:users"},  // Required for fetching user-to-role memberships
+				{Permission: "write:users"}, // Required for granting and revoking roles
 			},
 		},
 		&v2.SkipEntitlements{},
@@ -44,6 +52,88 @@ var projectResourceType = &v2.ResourceType{
 		&v2.CapabilityPermissions{
 			Permissions: []*v2.CapabilityPermission{
 				{Permission: "read:projects"},
+				{Permission: "write:projects"}, // Required for granting and revoking project owners and champions
+				{Permission: "write:users"},    // Required for granting and revoking project members
+			},
+		},
+		&v2.SkipEntitlements{},
+	),
+}
+
+// projectRoleResourceType represents a project-scoped role bound to one project.
+// Project roles are children of projects.
+var projectRoleResourceType = &v2.ResourceType{
+	Id:          "project-role",
+	DisplayName: "Project Role",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SCOPE_BINDING},
+	Annotations: annotations.New(
+		&v2.CapabilityPermissions{
+			Permissions: []*v2.CapabilityPermission{
+				{Permission: "read:users"},  // Required for fetching user roles and project assignments
+				{Permission: "write:users"}, // Required for granting and revoking project roles
+			},
+		},
+		&v2.SkipEntitlements{},
+	),
+}
+
+// serviceAccountResourceType represents Wiz service accounts (API clients).
+// Service accounts are non-human identities and also carry a SecretTrait describing their client secret.
+var serviceAccountResourceType = &v2.ResourceType{
+	Id:          "service-account",
+	DisplayName: "Service Account",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER, v2.ResourceType_TRAIT_SECRET},
+	Annotations: annotations.New(
+		&v2.CapabilityPermissions{
+			Permissions: []*v2.CapabilityPermission{
+				{Permission: "read:service_accounts"},
+				{Permission: "write:service_accounts"}, // Required for rotating client secrets
+			},
+		},
+		&v2.SkipEntitlements{},
+	),
+}
+
+// permissionResourceType represents Wiz API scopes such as read:issues.
+var permissionResourceType = &v2.ResourceType{
+	Id:          "permission",
+	DisplayName: "Permission",
+	Annotations: annotations.New(
+		&v2.CapabilityPermissions{
+			Permissions: []*v2.CapabilityPermission{
+				{Permission: "read:service_accounts"},
+			},
+		},
+		&v2.SkipEntitlements{},
+	),
+}
+
+// cloudAccountResourceType represents the cloud accounts connected to Wiz (AWS accounts, Azure subscriptions, GCP projects, OCI tenancies).
+// The app trait carries the profile linking each account to its projects.
+var cloudAccountResourceType = &v2.ResourceType{
+	Id:          "cloud-account",
+	DisplayName: "Cloud Account",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+	Annotations: annotations.New(
+		&v2.CapabilityPermissions{
+			Permissions: []*v2.CapabilityPermission{
+				{Permission: "read:cloud_accounts"},
+			},
+		},
+		&v2.SkipEntitlements{},
+	),
+}
+
+// samlGroupMappingResourceType represents the identity provider groups mapped to Wiz roles for SSO sign-in.
+// Members of the group get the mapped role, on the mapped projects if any, when they sign in through the provider.
+var samlGroupMappingResourceType = &v2.ResourceType{
+	Id:          "saml-group-mapping",
+	DisplayName: "SAML Group Mapping",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+	Annotations: annotations.New(
+		&v2.CapabilityPermissions{
+			Permissions: []*v2.CapabilityPermission{
+				{Permission: "read:saml_identity_providers"},
 			},
 		},
 		&v2.SkipEntitlements{},
@@ -64,3 +154,20 @@ var securityInsightResourceType = &v2.ResourceType{
 		&v2.SkipEntitlementsAndGrants{},
 	),
 }
+
+// skipWithoutPermission reports whether err means the Wiz credentials lack the permission needed to list a resource
+// type, logging a warning if so. Resource types added after the user, project and role types need scopes that existing
+// installs may not have been granted, so their List returns nothing rather than failing the whole sync.
+func skipWithoutPermission(ctx context.Context, err error, resourceType *v2.ResourceType, permission string) bool {
+	if status.Code(err) != codes.PermissionDenied {
+		return false
+	}
+
+	ctxzap.Extract(ctx).Warn(
+		"wiz-connector: skipping resources the Wiz credentials lack the permission to list",
+		zap.String("resource_type", resourceType.Id),
+		zap.String("permission", permission),
+		zap.Error(err),
+	)
+	return true
+}
@@ -3,15 +3,26 @@ package connector
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type roleBuilder struct {
-	client wiz.Client
+	client         wiz.Client
+	fallbackRoleID string
 }
 
 func (r *roleBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -35,12 +46,7 @@ func (r *roleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 	}
 
 	for _, role := range resp.Nodes {
-		roleResource, err := resource.NewRoleResource(
-			role.Name,
-			roleResourceType,
-			role.ID,
-			[]resource.RoleTraitOption{},
-		)
+		roleResource, err := newRoleResource(&role)
 		if err != nil {
 			return nil, nil, fmt.Errorf("wiz-connector: failed to create role resource: %w", err)
 		}
@@ -57,6 +63,21 @@ func (r *roleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 	return resources, syncResults, nil
 }
 
+// Get returns a single role by ID, e.g. to refresh it after provisioning without a full sync.
+func (r *roleBuilder) Get(ctx context.Context, resourceId *v2.ResourceId, parentResourceId *v2.ResourceId) (*v2.Resource, annotations.Annotations, error) {
+	role, err := r.findRole(ctx, resourceId.GetResource())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roleResource, err := newRoleResource(role)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to create role resource: %w", err)
+	}
+
+	return roleResource, nil, nil
+}
+
 // StaticEntitlements returns a static "member" entitlement template for all roles.
 // The SDK will expand this template for each role resource, constructing the ID, display name, and description.
 func (r *roleBuilder) StaticEntitlements(ctx context.Context, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
@@ -81,13 +102,338 @@ func (r *roleBuilder) Entitlements(ctx context.Context, res *v2.Resource, _ reso
 	return nil, nil, nil
 }
 
-// Grants returns grants for users who have this role.
-// Role grants are now emitted from the user resource type to avoid querying all users for each role.
+// Grants returns the permission grants of this role, one per scope read from the role profile populated during List().
+// Grants of role membership are emitted from the user resource type to avoid querying all users for each role.
 // See users.go Grants() method.
 func (r *roleBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
-	return nil, nil, nil
+	var grants []*v2.Grant
+
+	roleTrait, err := resource.GetRoleTrait(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get role trait: %w", err)
+	}
+
+	scopes, ok := roleTrait.GetProfile().GetFields()["scopes"]
+	if !ok {
+		return grants, nil, nil
+	}
+
+	// Role members hold every scope of the role, so the grants expand to them
+	memberEntitlementID := fmt.Sprintf("%s:%s:member", roleResourceType.Id, res.GetId().GetResource())
+	for _, scope := range profileStrings(scopes) {
+		permissionResource, err := newPermissionResource(scope)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create permission resource: %w", err)
+		}
+
+		grants = append(grants, grant.NewGrant(
+			permissionResource,
+			"assigned",
+			res.GetId(),
+			grant.WithAnnotation(&v2.GrantExpandable{EntitlementIds: []string{memberEntitlementID}}),
+		))
+	}
+
+	return grants, nil, nil
+}
+
+// Grant assigns the role to a user by setting the user's role in Wiz.
+// Wiz users hold exactly one role, so granting a role replaces the user's current role.
+func (r *roleBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.GetId().GetResourceType() != userResourceType.Id {
+		return nil, nil, status.Error(codes.FailedPrecondition, "wiz-connector: only users can be granted role membership")
+	}
+
+	roleID := entitlement.GetResource().GetId().GetResource()
+	email := principal.GetId().GetResource()
+
+	user, err := r.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	newGrant := grant.NewGrant(entitlement.GetResource(), "member", principal.GetId())
+
+	if user.EffectiveRole.ID == roleID {
+		l.Debug("wiz-connector: user already has role", zap.String("user", email), zap.String("role_id", roleID))
+		return []*v2.Grant{newGrant}, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	// Project-scoped roles are granted on a project through the project role entitlement, see projectRoleBuilder.Grant
+	if err := setUserRole(ctx, r.client, user, roleID, nil); err != nil {
+		return nil, nil, err
+	}
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+// Revoke removes the role from a user by reverting them to the configured fallback role.
+// Grants to SAML group mappings come from the identity provider configuration and cannot be revoked here.
+func (r *roleBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if g.GetPrincipal().GetId().GetResourceType() != userResourceType.Id {
+		return nil, status.Error(codes.FailedPrecondition, "wiz-connector: only role membership of users can be revoked")
+	}
+
+	roleID := g.GetEntitlement().GetResource().GetId().GetResource()
+	email := g.GetPrincipal().GetId().GetResource()
+
+	if r.fallbackRoleID == "" {
+		return nil, status.Error(codes.FailedPrecondition, "wiz-connector: revoking a role requires wiz-fallback-role-id to be configured")
+	}
+	if r.fallbackRoleID == roleID {
+		return nil, status.Errorf(codes.FailedPrecondition, "wiz-connector: cannot revoke role %s because it is the configured fallback role", roleID)
+	}
+
+	user, err := r.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	if user.EffectiveRole.ID != roleID {
+		l.Debug("wiz-connector: user does not have role", zap.String("user", email), zap.String("role_id", roleID))
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	if err := setUserRole(ctx, r.client, user, r.fallbackRoleID, nil); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// setUserRole updates the user's role. Project-scoped roles only apply within the projects they are granted on, so
+// those projects must be given explicitly; they are added to the user's assigned projects in the same update.
+// The user's current projects are not used instead, as that would silently widen the role to all of them.
+func setUserRole(ctx context.Context, client wiz.Client, user *wiz.User, roleID string, projectIDs []string) error {
+	role, err := client.GetRole(ctx, roleID)
+	if err != nil {
+		return fmt.Errorf("wiz-connector: failed to get role %s: %w", roleID, err)
+	}
+
+	if role.IsProjectScoped && len(projectIDs) == 0 {
+		return status.Errorf(
+			codes.InvalidArgument,
+			"wiz-connector: role %q is project-scoped and requires a project; grant it through the %s entitlement of the project instead",
+			role.Name,
+			projectRoleResourceType.Id,
+		)
+	}
+
+	patch := wiz.UpdateUserPatch{Role: &role.ID}
+	if len(projectIDs) > 0 {
+		ids := assignedProjectIDs(user)
+		for _, projectID := range projectIDs {
+			if !slices.Contains(ids, projectID) {
+				ids = append(ids, projectID)
+			}
+		}
+		patch.AssignedProjectIDs = &ids
+	}
+
+	if err := client.UpdateUser(ctx, user.ID, patch); err != nil {
+		return fmt.Errorf("wiz-connector: failed to set role %s for user %s: %w", role.ID, user.Email, err)
+	}
+
+	return nil
 }
 
-func newRoleBuilder(client wiz.Client) *roleBuilder {
-	return &roleBuilder{client: client}
+// assignedProjectIDs returns the IDs of the projects the user is directly assigned to.
+func assignedProjectIDs(user *wiz.User) []string {
+	ids := make([]string, 0, len(user.AssignedProjects))
+	for _, project := range user.AssignedProjects {
+		ids = append(ids, project.ID)
+	}
+	return ids
+}
+
+// findRole returns the role with the given ID.
+func (r *roleBuilder) findRole(ctx context.Context, roleID string) (*wiz.UserRole, error) {
+	role, err := r.client.GetRole(ctx, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to get role %s: %w", roleID, err)
+	}
+
+	return role, nil
+}
+
+// Create creates a custom Wiz role from the resource's display name, description, and the "scopes" and
+// "is_project_scoped" fields of its role profile.
+func (r *roleBuilder) Create(ctx context.Context, res *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	name := res.GetDisplayName()
+	if name == "" {
+		return nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: a name is required to create a role")
+	}
+
+	var profile *structpb.Struct
+	if roleTrait, err := resource.GetRoleTrait(res); err == nil {
+		profile = roleTrait.GetProfile()
+	}
+
+	scopes := profileStrings(profile.GetFields()["scopes"])
+	if len(scopes) == 0 {
+		return nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: at least one scope is required to create a role")
+	}
+
+	role, err := r.client.CreateUserRole(ctx, wiz.CreateUserRoleInput{
+		Name:            name,
+		Description:     res.GetDescription(),
+		Scopes:          scopes,
+		IsProjectScoped: profile.GetFields()["is_project_scoped"].GetBoolValue(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to create role %s: %w", name, err)
+	}
+
+	roleResource, err := newRoleResource(role)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to create role resource: %w", err)
+	}
+
+	return roleResource, nil, nil
+}
+
+// Delete removes a custom Wiz role. Built-in roles cannot be deleted.
+func (r *roleBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId, parentResourceID *v2.ResourceId) (annotations.Annotations, error) {
+	role, err := r.findCustomRole(ctx, resourceId.GetResource())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.client.DeleteUserRole(ctx, role.ID); err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to delete role %s: %w", role.ID, err)
+	}
+
+	return nil, nil
+}
+
+// ResourceActions registers the update_role action, which changes the name, description, or scopes of a custom role.
+func (r *roleBuilder) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	return registry.Register(ctx, updateRoleActionSchema, r.updateRole)
+}
+
+var updateRoleActionSchema = v2.BatonActionSchema_builder{
+	Name:        "update_role",
+	DisplayName: "Update Role",
+	Description: "Update the name, description, or scopes of a custom Wiz role. Omitted fields are left unchanged",
+	Arguments: []*config.Field{
+		config.Field_builder{
+			Name:            "resource",
+			DisplayName:     "Role",
+			Description:     "The custom role to update",
+			IsRequired:      true,
+			ResourceIdField: &config.ResourceIdField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "name",
+			DisplayName: "Name",
+			Description: "New name of the role",
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:        "description",
+			DisplayName: "Description",
+			Description: "New description of the role",
+			StringField: &config.StringField{},
+		}.Build(),
+		config.Field_builder{
+			Name:             "scopes",
+			DisplayName:      "Scopes",
+			Description:      "Wiz API scopes granted by the role, replacing the current scopes",
+			StringSliceField: &config.StringSliceField{},
+		}.Build(),
+	},
+	ReturnTypes: []*config.Field{
+		config.Field_builder{Name: "success", BoolField: &config.BoolField{}}.Build(),
+		config.Field_builder{Name: "resource", ResourceField: &config.ResourceField{}}.Build(),
+	},
+	ActionType: []v2.ActionType{v2.ActionType_ACTION_TYPE_DYNAMIC},
+}.Build()
+
+// updateRole handles the update_role action.
+func (r *roleBuilder) updateRole(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	resourceID, err := actions.RequireResourceIDArg(args, "resource")
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "wiz-connector: %s", err)
+	}
+
+	patch := wiz.UpdateUserRolePatch{}
+	if name, ok := actions.GetStringArg(args, "name"); ok && name != "" {
+		patch.Name = &name
+	}
+	if description, ok := actions.GetStringArg(args, "description"); ok {
+		patch.Description = &description
+	}
+	if scopes, ok := actions.GetStringSliceArg(args, "scopes"); ok {
+		if len(scopes) == 0 {
+			return nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: a role requires at least one scope")
+		}
+		patch.Scopes = &scopes
+	}
+
+	role, err := r.findCustomRole(ctx, resourceID.GetResource())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := r.client.UpdateUserRole(ctx, role.ID, patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to update role %s: %w", role.ID, err)
+	}
+
+	roleResource, err := newRoleResource(updated)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to create role resource: %w", err)
+	}
+	resourceField, err := actions.NewResourceReturnField("resource", roleResource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to build action result: %w", err)
+	}
+
+	return actions.NewReturnValues(true, resourceField), nil, nil
+}
+
+// findCustomRole returns the role with the given ID, refusing built-in roles, which Wiz does not allow to be modified.
+func (r *roleBuilder) findCustomRole(ctx context.Context, roleID string) (*wiz.UserRole, error) {
+	role, err := r.findRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Builtin {
+		return nil, status.Errorf(codes.FailedPrecondition, "wiz-connector: role %q is a built-in Wiz role and cannot be modified", role.Name)
+	}
+	return role, nil
+}
+
+// newRoleResource creates a role resource, storing its scopes in the profile for use in Grants().
+func newRoleResource(role *wiz.UserRole) (*v2.Resource, error) {
+	roleOpts := []resource.RoleTraitOption{
+		resource.WithRoleProfile(map[string]interface{}{
+			"scopes":            toInterfaceSlice(role.Scopes),
+			"builtin":           role.Builtin,
+			"is_project_scoped": role.IsProjectScoped,
+		}),
+	}
+	if role.IsProjectScoped {
+		// Project-scoped roles only apply within the projects they are assigned on, see project_roles.go
+		roleOpts = append(roleOpts, resource.WithRoleScopeConditions(projectResourceType.Id, nil))
+	}
+
+	return resource.NewRoleResource(
+		role.Name,
+		roleResourceType,
+		role.ID,
+		roleOpts,
+		resource.WithDescription(role.Description),
+	)
+}
+
+func newRoleBuilder(client wiz.Client, fallbackRoleID string) *roleBuilder {
+	return &roleBuilder{
+		client:         client,
+		fallbackRoleID: fallbackRoleID,
+	}
 }
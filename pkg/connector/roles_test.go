@@ -0,0 +1,300 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRoleScopesSyncedAsPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.roles = []wiz.UserRole{
+		{ID: "SECURITY_ADMIN", Name: "Security Admin", Scopes: []string{"read:issues", "write:security_scans"}},
+	}
+	client.serviceAccounts = []wiz.ServiceAccount{
+		{ID: "sa-1", Name: "ci", Scopes: []string{"read:issues", "read:users"}},
+	}
+
+	permissions, _, err := newPermissionBuilder(client).List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var permissionIDs []string
+	for _, p := range permissions {
+		permissionIDs = append(permissionIDs, p.GetId().GetResource())
+	}
+	assert.Equal(t, []string{"read:issues", "read:users", "write:security_scans"}, permissionIDs)
+
+	builder := newRoleBuilder(client, "")
+	roles, _, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, roles, 1) {
+		return
+	}
+
+	grants, _, err := builder.Grants(ctx, roles[0], resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, grants, 2) {
+		return
+	}
+	assert.Equal(t, "permission:read:issues:assigned", grants[0].GetEntitlement().GetId())
+	assert.Equal(t, "permission:write:security_scans:assigned", grants[1].GetEntitlement().GetId())
+	assert.Equal(t, "SECURITY_ADMIN", grants[1].GetPrincipal().GetId().GetResource())
+
+	// Role members inherit the role's permissions.
+	expandable := &v2.GrantExpandable{}
+	annos := annotations.Annotations(grants[1].GetAnnotations())
+	ok, err := annos.Pick(expandable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ok)
+	assert.Equal(t, []string{"role:SECURITY_ADMIN:member"}, expandable.GetEntitlementIds())
+}
+
+func TestCustomRoleManagement(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.roles = []wiz.UserRole{
+		{ID: "GLOBAL_ADMIN", Name: "Global Admin", Scopes: []string{"admin:all"}, Builtin: true},
+	}
+	builder := newRoleBuilder(client, "")
+
+	roleResource, err := resource.NewRoleResource(
+		"Scan Operator",
+		roleResourceType,
+		"",
+		[]resource.RoleTraitOption{
+			resource.WithRoleProfile(map[string]interface{}{
+				"scopes": []interface{}{"read:issues", "write:security_scans"},
+			}),
+		},
+		resource.WithDescription("Runs security scans"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, _, err := builder.Create(ctx, roleResource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roleID := created.GetId().GetResource()
+	assert.Equal(t, "Scan Operator", created.GetDisplayName())
+	assert.Equal(t, []string{"read:issues", "write:security_scans"}, client.roles[1].Scopes)
+	assert.Equal(t, "Runs security scans", client.roles[1].Description)
+
+	t.Run("update custom role", func(t *testing.T) {
+		args, err := structpb.NewStruct(map[string]interface{}{
+			"resource": map[string]interface{}{"resource_type_id": roleResourceType.Id, "resource_id": roleID},
+			"name":     "Scan Admin",
+			"scopes":   []interface{}{"write:security_scans"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, _, err := builder.updateRole(ctx, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result.GetFields()["success"].GetBoolValue())
+		assert.Equal(t, "Scan Admin", client.roles[1].Name)
+		assert.Equal(t, "Runs security scans", client.roles[1].Description)
+		assert.Equal(t, []string{"write:security_scans"}, client.roles[1].Scopes)
+	})
+
+	t.Run("built-in roles are refused", func(t *testing.T) {
+		updates := client.callCount("UpdateUserRole")
+		args, err := structpb.NewStruct(map[string]interface{}{
+			"resource": map[string]interface{}{"resource_type_id": roleResourceType.Id, "resource_id": "GLOBAL_ADMIN"},
+			"name":     "Renamed",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = builder.updateRole(ctx, args)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = builder.Delete(ctx, &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: "GLOBAL_ADMIN"}, nil)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, updates, client.callCount("UpdateUserRole"))
+		assert.Equal(t, 0, client.callCount("DeleteUserRole"))
+	})
+
+	t.Run("delete custom role", func(t *testing.T) {
+		_, err := builder.Delete(ctx, created.GetId(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, client.roles, 1)
+	})
+}
+
+func TestRoleGrantAndRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		revoke   bool
+		roleID   string
+		fallback string
+		// userRoleID and userProjects describe the user before the grant or revoke.
+		userRoleID   string
+		userProjects []wiz.ProjectRef
+		wantCode     codes.Code
+		wantAnno     proto.Message
+		wantRoleID   string
+	}{
+		{
+			name:       "grant replaces the user's role",
+			roleID:     "GLOBAL_ADMIN",
+			userRoleID: "GLOBAL_READER",
+			wantRoleID: "GLOBAL_ADMIN",
+		},
+		{
+			name:       "grant of the user's current role",
+			roleID:     "GLOBAL_READER",
+			userRoleID: "GLOBAL_READER",
+			wantAnno:   &v2.GrantAlreadyExists{},
+			wantRoleID: "GLOBAL_READER",
+		},
+		{
+			// The project must be given through the project role entitlement; the user's projects are not used.
+			name:         "grant of a project-scoped role to a user with projects",
+			roleID:       "PROJECT_ADMIN",
+			userRoleID:   "GLOBAL_READER",
+			userProjects: []wiz.ProjectRef{{ID: "project-1"}},
+			wantCode:     codes.InvalidArgument,
+			wantRoleID:   "GLOBAL_READER",
+		},
+		{
+			name:       "grant of a project-scoped role to a user without projects",
+			roleID:     "PROJECT_ADMIN",
+			userRoleID: "GLOBAL_READER",
+			wantCode:   codes.InvalidArgument,
+			wantRoleID: "GLOBAL_READER",
+		},
+		{
+			name:       "revoke reverts to the fallback role",
+			revoke:     true,
+			roleID:     "GLOBAL_ADMIN",
+			fallback:   "GLOBAL_READER",
+			userRoleID: "GLOBAL_ADMIN",
+			wantRoleID: "GLOBAL_READER",
+		},
+		{
+			name:       "revoke without a fallback role",
+			revoke:     true,
+			roleID:     "GLOBAL_ADMIN",
+			userRoleID: "GLOBAL_ADMIN",
+			wantCode:   codes.FailedPrecondition,
+			wantRoleID: "GLOBAL_ADMIN",
+		},
+		{
+			name:       "revoke of the fallback role",
+			revoke:     true,
+			roleID:     "GLOBAL_READER",
+			fallback:   "GLOBAL_READER",
+			userRoleID: "GLOBAL_READER",
+			wantCode:   codes.FailedPrecondition,
+			wantRoleID: "GLOBAL_READER",
+		},
+		{
+			name:       "revoke of a role the user does not have",
+			revoke:     true,
+			roleID:     "GLOBAL_ADMIN",
+			fallback:   "GLOBAL_READER",
+			userRoleID: "PROJECT_ADMIN",
+			wantAnno:   &v2.GrantAlreadyRevoked{},
+			wantRoleID: "PROJECT_ADMIN",
+		},
+		{
+			name:       "revoke to a project-scoped fallback role",
+			revoke:     true,
+			roleID:     "GLOBAL_ADMIN",
+			fallback:   "PROJECT_ADMIN",
+			userRoleID: "GLOBAL_ADMIN",
+			wantCode:   codes.InvalidArgument,
+			wantRoleID: "GLOBAL_ADMIN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient()
+			client.roles = []wiz.UserRole{
+				{ID: "GLOBAL_READER", Name: "Global Reader"},
+				{ID: "GLOBAL_ADMIN", Name: "Global Admin"},
+				{ID: "PROJECT_ADMIN", Name: "Project Admin", IsProjectScoped: true},
+			}
+			client.users = []wiz.User{{
+				ID:                        "u1",
+				Email:                     "alice@example.com",
+				EffectiveRole:             wiz.UserRoleRef{ID: tt.userRoleID},
+				EffectiveAssignedProjects: tt.userProjects,
+			}}
+			builder := newRoleBuilder(client, tt.fallback)
+
+			roleResource, err := resource.NewRoleResource(tt.roleID, roleResourceType, tt.roleID, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "alice@example.com"}}
+
+			var annos annotations.Annotations
+			if tt.revoke {
+				annos, err = builder.Revoke(ctx, grant.NewGrant(roleResource, "member", principal.GetId()))
+			} else {
+				_, annos, err = builder.Grant(ctx, principal, ent.NewAssignmentEntitlement(roleResource, "member"))
+			}
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantAnno != nil {
+				assert.True(t, annos.Contains(tt.wantAnno))
+			} else {
+				assert.Empty(t, annos)
+			}
+			assert.Equal(t, tt.wantRoleID, client.users[0].EffectiveRole.ID)
+			if tt.wantRoleID == tt.userRoleID {
+				assert.Zero(t, client.callCount("UpdateUser"))
+			}
+		})
+	}
+}
+
+func TestRoleGet(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	client.roles = []wiz.UserRole{{ID: "PROJECT_MEMBER", Name: "Project Member", Scopes: []string{"read:issues"}, IsProjectScoped: true}}
+	builder := newRoleBuilder(client, "")
+
+	res, _, err := builder.Get(ctx, &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: "PROJECT_MEMBER"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Project Member", res.GetDisplayName())
+	assert.Equal(t, 1, client.callCount("GetRole"))
+	assert.Equal(t, 0, client.callCount("ListUserRoles"))
+
+	_, _, err = builder.Get(ctx, &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: "MISSING"}, nil)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,194 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+)
+
+type samlGroupMappingBuilder struct {
+	client wiz.Client
+	// expandProjectRoles also grants the per-project role entitlements, see projects.go StaticEntitlements.
+	expandProjectRoles bool
+}
+
+func (s *samlGroupMappingBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return samlGroupMappingResourceType
+}
+
+// List returns one resource per identity provider group mapped to a Wiz role, one page of identity providers at a time.
+// The roles and projects each group is mapped to are stored in the profile for use in Grants().
+// Nothing is returned when the credentials lack read:saml_identity_providers.
+func (s *samlGroupMappingBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	var groups []*v2.Resource
+
+	// Get the page token from the sync attributes
+	var cursor *string
+	if attr.PageToken.Token != "" {
+		cursor = &attr.PageToken.Token
+	}
+
+	// Fetch one page of identity providers
+	resp, err := s.client.ListSAMLIdentityProviders(ctx, cursor)
+	if skipWithoutPermission(ctx, err, samlGroupMappingResourceType, "read:saml_identity_providers") {
+		return nil, &resource.SyncOpResults{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to list saml identity providers: %w", err)
+	}
+
+	for _, idp := range resp.Nodes {
+		// A group can be mapped more than once, e.g. to a role on some projects and another role on others
+		var groupIDs []string
+		mappings := make(map[string][]wiz.SAMLGroupMapping)
+		for _, mapping := range idp.GroupMapping {
+			if mapping.ProviderGroupID == "" {
+				continue
+			}
+			if _, ok := mappings[mapping.ProviderGroupID]; !ok {
+				groupIDs = append(groupIDs, mapping.ProviderGroupID)
+			}
+			mappings[mapping.ProviderGroupID] = append(mappings[mapping.ProviderGroupID], mapping)
+		}
+
+		for _, groupID := range groupIDs {
+			groupResource, err := newSAMLGroupMappingResource(&idp, groupID, mappings[groupID])
+			if err != nil {
+				return nil, nil, fmt.Errorf("wiz-connector: failed to create saml group mapping resource: %w", err)
+			}
+
+			groups = append(groups, groupResource)
+		}
+	}
+
+	// Prepare the sync results with next page token if there are more pages
+	syncResults := &resource.SyncOpResults{}
+	if resp.PageInfo.HasNextPage {
+		syncResults.NextPageToken = resp.PageInfo.EndCursor
+	}
+
+	return groups, syncResults, nil
+}
+
+// StaticEntitlements returns a static "member" entitlement for all mapped groups.
+// Wiz does not know who is in an identity provider group; the entitlement is there for the group's role and project
+// grants to expand to, and is matched with the group synced by the identity provider's connector through the external ID.
+func (s *samlGroupMappingBuilder) StaticEntitlements(ctx context.Context, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	var entitlements []*v2.Entitlement
+	entitlements = append(
+		entitlements,
+		ent.NewAssignmentEntitlement(
+			nil,
+			"member",
+			ent.WithDisplayName("Group Member"),
+			ent.WithDescription("Member of an identity provider group mapped to a Wiz role"),
+			ent.WithGrantableTo(userResourceType),
+		),
+	)
+
+	return entitlements, nil, nil
+}
+
+// Entitlements is required by ResourceSyncerV2 but we use StaticEntitlements instead.
+// This should not be called due to the SkipEntitlements annotation on the resource type.
+func (s *samlGroupMappingBuilder) Entitlements(ctx context.Context, res *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// Grants returns the group's grants of each role it is mapped to and, for mappings limited to projects, of membership
+// of those projects and of the role's binding to them, mirroring users.go Grants().
+// The grants are expandable, so the members of the group are shown with the access the mapping gives them.
+func (s *samlGroupMappingBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
+	var grants []*v2.Grant
+
+	// Extract the group trait to get the mappings stored during List
+	groupTrait, err := resource.GetGroupTrait(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get group trait: %w", err)
+	}
+
+	expandable := grant.WithAnnotation(&v2.GrantExpandable{
+		EntitlementIds: []string{ent.NewEntitlementID(res, "member")},
+	})
+
+	for _, value := range groupTrait.GetProfile().GetFields()["mappings"].GetListValue().GetValues() {
+		mapping := value.GetStructValue().GetFields()
+		roleID := mapping["role_id"].GetStringValue()
+		if roleID == "" {
+			continue
+		}
+		roleProjectScoped := mapping["role_project_scoped"].GetBoolValue()
+
+		roleResource, err := resource.NewResource("", roleResourceType, roleID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create role resource: %w", err)
+		}
+		grants = append(grants, grant.NewGrant(roleResource, "member", res.Id, expandable))
+
+		memberGrantMetadata := grant.WithGrantMetadata(map[string]interface{}{
+			"role_id":   roleID,
+			"role_name": mapping["role_name"].GetStringValue(),
+		})
+		for _, projectID := range profileStrings(mapping["project_ids"]) {
+			projectResourceID := &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: projectID}
+			projectResource := &v2.Resource{Id: projectResourceID}
+			grants = append(grants, grant.NewGrant(projectResource, "member", res.Id, expandable, memberGrantMetadata))
+
+			if !roleProjectScoped {
+				continue
+			}
+			if s.expandProjectRoles {
+				grants = append(grants, grant.NewGrant(projectResource, roleID, res.Id, expandable))
+			}
+
+			projectRoleResource, err := newProjectRoleResource(roleID, "", projectResourceID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("wiz-connector: failed to create project role resource: %w", err)
+			}
+			grants = append(grants, grant.NewGrant(projectRoleResource, "member", res.Id, expandable))
+		}
+	}
+
+	return grants, nil, nil
+}
+
+// newSAMLGroupMappingResource creates a group resource for an identity provider group, keyed by the identity provider
+// and group IDs. The group ID is set as the external ID so it can be matched with the group in the identity provider.
+func newSAMLGroupMappingResource(idp *wiz.SAMLIdentityProvider, groupID string, mappings []wiz.SAMLGroupMapping) (*v2.Resource, error) {
+	profileMappings := make([]interface{}, 0, len(mappings))
+	for _, mapping := range mappings {
+		profileMappings = append(profileMappings, map[string]interface{}{
+			"role_id":             mapping.Role.ID,
+			"role_name":           mapping.Role.Name,
+			"role_project_scoped": mapping.Role.IsProjectScoped,
+			"project_ids":         toInterfaceSlice(activeProjectIDs(mapping.Projects)),
+		})
+	}
+
+	profile := map[string]interface{}{
+		"identity_provider_id":   idp.ID,
+		"identity_provider_name": idp.Name,
+		"provider_group_id":      groupID,
+		"mappings":               profileMappings,
+	}
+
+	return resource.NewGroupResource(
+		groupID,
+		samlGroupMappingResourceType,
+		fmt.Sprintf("%s:%s", idp.ID, groupID),
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+		resource.WithExternalID(&v2.ExternalId{Id: groupID}),
+		resource.WithDescription(fmt.Sprintf("%s group %s", idp.Name, groupID)),
+	)
+}
+
+func newSAMLGroupMappingBuilder(client wiz.Client, expandProjectRoles bool) *samlGroupMappingBuilder {
+	return &samlGroupMappingBuilder{client: client, expandProjectRoles: expandProjectRoles}
+}
@@ -0,0 +1,138 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSAMLGroupMappingGrants(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.samlIdentityProviders = []wiz.SAMLIdentityProvider{{
+		ID:   "idp-entra",
+		Name: "Entra ID",
+		GroupMapping: []wiz.SAMLGroupMapping{
+			{
+				ProviderGroupID: "security-team",
+				Role:            wiz.UserRoleRef{ID: "GLOBAL_READER", Name: "Global Reader"},
+			},
+			{
+				ProviderGroupID: "data-engineers",
+				Role:            wiz.UserRoleRef{ID: "PROJECT_MEMBER", Name: "Project Member", IsProjectScoped: true},
+				Projects:        []wiz.ProjectRef{{ID: "project-2", Name: "Data Platform"}},
+			},
+			{
+				// The same group mapped a second time is merged into one resource.
+				ProviderGroupID: "security-team",
+				Role:            wiz.UserRoleRef{ID: "PROJECT_ADMIN", Name: "Project Admin", IsProjectScoped: true},
+				Projects:        []wiz.ProjectRef{{ID: "project-1", Name: "Payments"}},
+			},
+		},
+	}}
+	builder := newSAMLGroupMappingBuilder(client, false)
+
+	resources, _, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, resources, 2) {
+		return
+	}
+	res := resources[0]
+	assert.Equal(t, "idp-entra:security-team", res.GetId().GetResource())
+	assert.Equal(t, "security-team", res.GetExternalId().GetId())
+	assert.Equal(t, "Entra ID group security-team", res.GetDescription())
+
+	grants, _, err := builder.Grants(ctx, res, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entitlementIDs []string
+	for _, g := range grants {
+		entitlementIDs = append(entitlementIDs, g.GetEntitlement().GetId())
+		assert.Equal(t, "idp-entra:security-team", g.GetPrincipal().GetId().GetResource())
+
+		// Every grant expands to the members of the group.
+		expandable := &v2.GrantExpandable{}
+		annos := annotations.Annotations(g.GetAnnotations())
+		ok, err := annos.Pick(expandable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, ok)
+		assert.Equal(t, []string{"saml-group-mapping:idp-entra:security-team:member"}, expandable.GetEntitlementIds())
+	}
+	assert.Equal(t, []string{
+		"role:GLOBAL_READER:member",
+		"role:PROJECT_ADMIN:member",
+		"project:project-1:member",
+		"project-role:project-1:PROJECT_ADMIN:member",
+	}, entitlementIDs)
+}
+
+func TestSAMLGroupMappingGrantsCannotBeRevoked(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.samlIdentityProviders = []wiz.SAMLIdentityProvider{{
+		ID:   "idp-okta",
+		Name: "Okta",
+		GroupMapping: []wiz.SAMLGroupMapping{{
+			ProviderGroupID: "payments-engineers",
+			Role:            wiz.UserRoleRef{ID: "PROJECT_MEMBER", Name: "Project Member", IsProjectScoped: true},
+			Projects:        []wiz.ProjectRef{{ID: "project-1", Name: "Payments"}},
+		}},
+	}}
+	builder := newSAMLGroupMappingBuilder(client, false)
+
+	resources, _, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants, _, err := builder.Grants(ctx, resources[0], resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roles := newRoleBuilder(client, "GLOBAL_READER")
+	projects := newProjectBuilder(client, false)
+	for _, g := range grants {
+		switch g.GetEntitlement().GetResource().GetId().GetResourceType() {
+		case roleResourceType.Id:
+			_, err = roles.Revoke(ctx, g)
+		case projectResourceType.Id:
+			_, err = projects.Revoke(ctx, g)
+		default:
+			continue
+		}
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err), g.GetEntitlement().GetId())
+	}
+	assert.Equal(t, 0, client.callCount("GetUserByEmail"))
+}
+
+func TestSAMLGroupMappingsSkippedWithoutPermission(t *testing.T) {
+	ctx := context.Background()
+
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	server.InjectFault("ListSAMLIdentityProviders", wiztest.Fault{Errors: []wiztest.Error{{Message: "missing read:saml_identity_providers", Code: "FORBIDDEN"}}})
+	client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, _, err := newSAMLGroupMappingBuilder(client, false).List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, resources)
+}
@@ -0,0 +1,184 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type serviceAccountBuilder struct {
+	client wiz.Client
+}
+
+func (s *serviceAccountBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return serviceAccountResourceType
+}
+
+// List returns service accounts from Wiz as resource objects, one page at a time.
+// Nothing is returned when the credentials lack read:service_accounts.
+func (s *serviceAccountBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, attr resource.SyncOpAttrs) ([]*v2.Resource, *resource.SyncOpResults, error) {
+	var serviceAccounts []*v2.Resource
+
+	// Get the page token from the sync attributes
+	var cursor *string
+	if attr.PageToken.Token != "" {
+		cursor = &attr.PageToken.Token
+	}
+
+	// Fetch one page of service accounts
+	resp, err := s.client.ListServiceAccounts(ctx, cursor)
+	if skipWithoutPermission(ctx, err, serviceAccountResourceType, "read:service_accounts") {
+		return nil, &resource.SyncOpResults{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to list service accounts: %w", err)
+	}
+
+	for _, serviceAccount := range resp.Nodes {
+		serviceAccountResource, err := newServiceAccountResource(&serviceAccount)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create service account resource: %w", err)
+		}
+
+		serviceAccounts = append(serviceAccounts, serviceAccountResource)
+	}
+
+	// Prepare the sync results with next page token if there are more pages
+	syncResults := &resource.SyncOpResults{}
+	if resp.PageInfo.HasNextPage {
+		syncResults.NextPageToken = resp.PageInfo.EndCursor
+	}
+
+	return serviceAccounts, syncResults, nil
+}
+
+// Entitlements returns an empty slice as service accounts don't have child entitlements.
+func (s *serviceAccountBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+// Grants returns a permission grant for each scope held by this service account.
+// Scopes are read from the profile that was populated during List().
+func (s *serviceAccountBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
+	var grants []*v2.Grant
+
+	userTrait, err := resource.GetUserTrait(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get user trait: %w", err)
+	}
+
+	scopes, ok := userTrait.GetProfile().GetFields()["scopes"]
+	if !ok {
+		return grants, nil, nil
+	}
+
+	for _, scope := range profileStrings(scopes) {
+		permissionResource, err := newPermissionResource(scope)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wiz-connector: failed to create permission resource: %w", err)
+		}
+
+		grants = append(grants, grant.NewGrant(permissionResource, "assigned", res.Id))
+	}
+
+	return grants, nil, nil
+}
+
+// Rotate issues a new client secret for the service account and returns it for the SDK to encrypt.
+// Wiz generates the secret, so only randomly generated credentials are supported.
+func (s *serviceAccountBuilder) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.LocalCredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	if credentialOptions != nil && credentialOptions.GetRandomPassword() == nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: service account secrets are generated by Wiz; only random password credential options are supported")
+	}
+
+	credentials, err := s.client.RotateServiceAccountSecret(ctx, resourceId.GetResource())
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to rotate service account secret: %w", err)
+	}
+
+	return []*v2.PlaintextData{
+		{
+			Name:        "client_secret",
+			Description: fmt.Sprintf("Client secret for Wiz service account client ID %s", credentials.ClientID),
+			Bytes:       []byte(credentials.ClientSecret),
+		},
+	}, nil, nil
+}
+
+// RotateCapabilityDetails reports that rotated secrets are randomly generated by Wiz.
+func (s *serviceAccountBuilder) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}, nil, nil
+}
+
+// newServiceAccountResource creates a service account resource.
+// The SecretTrait describes the account's client secret so stale credentials can be identified.
+func newServiceAccountResource(serviceAccount *wiz.ServiceAccount) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"client_id": serviceAccount.ClientID,
+		"type":      serviceAccount.Type,
+		"scopes":    toInterfaceSlice(serviceAccount.Scopes),
+	}
+
+	userStatus := v2.UserTrait_Status_STATUS_ENABLED
+	if serviceAccount.Enabled != nil && !*serviceAccount.Enabled {
+		userStatus = v2.UserTrait_Status_STATUS_DISABLED
+	}
+
+	// The client secret is (re)issued when the account is created and on every rotation.
+	secretCreatedAt := serviceAccount.CreatedAt
+	if serviceAccount.LastRotatedAt != nil {
+		secretCreatedAt = *serviceAccount.LastRotatedAt
+	}
+
+	secretOpts := []resource.SecretTraitOption{
+		resource.WithSecretCreatedAt(secretCreatedAt),
+	}
+	if serviceAccount.LastUsedAt != nil {
+		secretOpts = append(secretOpts, resource.WithSecretLastUsedAt(*serviceAccount.LastUsedAt))
+	}
+	if serviceAccount.ExpiresAt != nil {
+		secretOpts = append(secretOpts, resource.WithSecretExpiresAt(*serviceAccount.ExpiresAt))
+	}
+	if serviceAccount.CreatedBy != nil && serviceAccount.CreatedBy.Email != "" {
+		// Users are keyed by email, see users.go
+		createdBy, err := resource.NewResourceID(userResourceType, serviceAccount.CreatedBy.Email)
+		if err != nil {
+			return nil, err
+		}
+		secretOpts = append(secretOpts, resource.WithSecretCreatedByID(createdBy))
+	}
+
+	return resource.NewUserResource(
+		serviceAccount.Name,
+		serviceAccountResourceType,
+		serviceAccount.ID,
+		[]resource.UserTraitOption{
+			resource.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_SERVICE),
+			resource.WithStatus(userStatus),
+			resource.WithUserLogin(serviceAccount.ClientID),
+			resource.WithCreatedAt(serviceAccount.CreatedAt),
+			resource.WithUserProfile(profile),
+		},
+		resource.WithSecretTrait(secretOpts...),
+	)
+}
+
+func newServiceAccountBuilder(client wiz.Client) *serviceAccountBuilder {
+	return &serviceAccountBuilder{client: client}
+}
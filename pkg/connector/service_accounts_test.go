@@ -0,0 +1,128 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceAccountSecretTraitAndGrants(t *testing.T) {
+	ctx := context.Background()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotatedAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	lastUsedAt := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	client := newFakeClient()
+	client.serviceAccounts = []wiz.ServiceAccount{{
+		ID:            "sa-1",
+		Name:          "baton",
+		ClientID:      "client-1",
+		Scopes:        []string{"read:users", "read:issues"},
+		CreatedAt:     createdAt,
+		LastRotatedAt: &rotatedAt,
+		LastUsedAt:    &lastUsedAt,
+		CreatedBy:     &wiz.UserRef{ID: "u1", Email: "admin@example.com"},
+	}}
+	builder := newServiceAccountBuilder(client)
+
+	resources, _, err := builder.List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, resources, 1) {
+		return
+	}
+	res := resources[0]
+
+	secretTrait := &v2.SecretTrait{}
+	annos := annotations.Annotations(res.GetAnnotations())
+	ok, err := annos.Pick(secretTrait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ok)
+	assert.Equal(t, rotatedAt, secretTrait.GetCreatedAt().AsTime())
+	assert.Equal(t, lastUsedAt, secretTrait.GetLastUsedAt().AsTime())
+	assert.Nil(t, secretTrait.GetExpiresAt())
+	assert.Equal(t, "admin@example.com", secretTrait.GetCreatedById().GetResource())
+
+	grants, _, err := builder.Grants(ctx, res, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, grants, 2) {
+		assert.Equal(t, "permission:read:users:assigned", grants[0].GetEntitlement().GetId())
+		assert.Equal(t, "permission:read:issues:assigned", grants[1].GetEntitlement().GetId())
+	}
+}
+
+func TestServiceAccountRotate(t *testing.T) {
+	ctx := context.Background()
+
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := newServiceAccountBuilder(client)
+
+	resourceID := &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "sa-1"}
+	plaintexts, _, err := builder.Rotate(ctx, resourceID, &v2.LocalCredentialOptions{
+		Options: &v2.LocalCredentialOptions_RandomPassword_{RandomPassword: &v2.LocalCredentialOptions_RandomPassword{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, plaintexts, 1) {
+		assert.Equal(t, "client_secret", plaintexts[0].GetName())
+		assert.NotEmpty(t, plaintexts[0].GetBytes())
+	}
+	assert.Equal(t, 1, server.Calls("RotateServiceAccountSecret"))
+
+	_, _, err = builder.Rotate(ctx, &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "missing"}, nil)
+	assert.Error(t, err)
+}
+
+func TestServiceAccountRotateRejectsPlaintextPassword(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	builder := newServiceAccountBuilder(client)
+
+	_, _, err := builder.Rotate(ctx, &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "sa-1"}, &v2.LocalCredentialOptions{
+		Options: &v2.LocalCredentialOptions_PlaintextPassword_{PlaintextPassword: &v2.LocalCredentialOptions_PlaintextPassword{PlaintextPassword: "hunter2"}},
+	})
+	assert.Error(t, err)
+	assert.Zero(t, client.callCount("RotateServiceAccountSecret"))
+}
+
+func TestServiceAccountsSkippedWithoutPermission(t *testing.T) {
+	ctx := context.Background()
+
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	server.InjectFault("ListServiceAccounts", wiztest.Fault{Errors: []wiztest.Error{{Message: "missing read:service_accounts", Code: "FORBIDDEN"}}})
+	client, err := wiz.NewClient(ctx, server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceAccounts, _, err := newServiceAccountBuilder(client).List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, serviceAccounts)
+
+	// Role scopes are still listed as permissions.
+	permissions, _, err := newPermissionBuilder(client).List(ctx, nil, resource.SyncOpAttrs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, permissions)
+}
@@ -0,0 +1,200 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+)
+
+// stateFileExt is the extension of the files holding values in a dirSessionStore. Other files, such as the temporary
+// files of interrupted writes, are ignored.
+const stateFileExt = ".json"
+
+// dirSessionStore is a sessions.SessionStore that keeps each value in a file of a local directory, so values outlive
+// the sync that wrote them, unlike the SDK session store which lives in the c1z file of a single sync.
+// Keys are escaped into file names and scopes set with sessions.WithSyncID are kept in subdirectories.
+// It is not safe for concurrent syncs sharing the same directory.
+type dirSessionStore struct {
+	dir string
+}
+
+var _ sessions.SessionStore = (*dirSessionStore)(nil)
+
+func newDirSessionStore(dir string) (*dirSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to create state directory %s: %w", dir, err)
+	}
+	return &dirSessionStore{dir: dir}, nil
+}
+
+func (d *dirSessionStore) bag(ctx context.Context, opt []sessions.SessionStoreOption) (*sessions.SessionStoreBag, error) {
+	bag := &sessions.SessionStoreBag{}
+	for _, o := range opt {
+		if err := o(ctx, bag); err != nil {
+			return nil, err
+		}
+	}
+	return bag, nil
+}
+
+// scopeDir returns the directory holding the values of the scope set in the bag.
+func (d *dirSessionStore) scopeDir(bag *sessions.SessionStoreBag) string {
+	return filepath.Join(d.dir, url.PathEscape(bag.SyncID))
+}
+
+func (d *dirSessionStore) path(bag *sessions.SessionStoreBag, key string) string {
+	return filepath.Join(d.scopeDir(bag), url.PathEscape(bag.Prefix+key)+stateFileExt)
+}
+
+func (d *dirSessionStore) Get(ctx context.Context, key string, opt ...sessions.SessionStoreOption) ([]byte, bool, error) {
+	bag, err := d.bag(ctx, opt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err := os.ReadFile(d.path(bag, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("wiz-connector: failed to read state %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+func (d *dirSessionStore) GetMany(ctx context.Context, keys []string, opt ...sessions.SessionStoreOption) (map[string][]byte, []string, error) {
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		value, found, err := d.Get(ctx, key, opt...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			values[key] = value
+		}
+	}
+	return values, nil, nil
+}
+
+// Set writes the value to a temporary file first and renames it into place, so an interrupted write leaves the
+// previous value intact.
+func (d *dirSessionStore) Set(ctx context.Context, key string, value []byte, opt ...sessions.SessionStoreOption) error {
+	bag, err := d.bag(ctx, opt)
+	if err != nil {
+		return err
+	}
+
+	dir := d.scopeDir(bag)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("wiz-connector: failed to create state directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("wiz-connector: failed to write state %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("wiz-connector: failed to write state %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("wiz-connector: failed to write state %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), d.path(bag, key)); err != nil {
+		return fmt.Errorf("wiz-connector: failed to write state %s: %w", key, err)
+	}
+	return nil
+}
+
+func (d *dirSessionStore) SetMany(ctx context.Context, values map[string][]byte, opt ...sessions.SessionStoreOption) error {
+	for key, value := range values {
+		if err := d.Set(ctx, key, value, opt...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *dirSessionStore) Delete(ctx context.Context, key string, opt ...sessions.SessionStoreOption) error {
+	bag, err := d.bag(ctx, opt)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(d.path(bag, key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("wiz-connector: failed to delete state %s: %w", key, err)
+	}
+	return nil
+}
+
+// Clear removes every value of the scope, or of the whole store when no scope is set.
+func (d *dirSessionStore) Clear(ctx context.Context, opt ...sessions.SessionStoreOption) error {
+	bag, err := d.bag(ctx, opt)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(d.scopeDir(bag)); err != nil {
+		return fmt.Errorf("wiz-connector: failed to clear state: %w", err)
+	}
+	return nil
+}
+
+// GetAll returns the values of the scope whose key starts with the prefix set in the options, with the prefix
+// removed from the keys. Values are returned in pages of sessions.MaxKeysPerRequest in key order; the page token is
+// the file name of the last value returned.
+func (d *dirSessionStore) GetAll(ctx context.Context, pageToken string, opt ...sessions.SessionStoreOption) (map[string][]byte, string, error) {
+	bag, err := d.bag(ctx, opt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dir := d.scopeDir(bag)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string][]byte{}, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("wiz-connector: failed to list state: %w", err)
+	}
+
+	// ReadDir returns the entries sorted by file name
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, stateFileExt) || name <= pageToken {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimSuffix(name, stateFileExt))
+		if err != nil || !strings.HasPrefix(key, bag.Prefix) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	nextPageToken := ""
+	if len(names) > sessions.MaxKeysPerRequest {
+		names = names[:sessions.MaxKeysPerRequest]
+		nextPageToken = names[len(names)-1]
+	}
+
+	values := make(map[string][]byte, len(names))
+	for _, name := range names {
+		value, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, "", fmt.Errorf("wiz-connector: failed to read state %s: %w", name, err)
+		}
+		key, _ := url.PathUnescape(strings.TrimSuffix(name, stateFileExt))
+		values[strings.TrimPrefix(key, bag.Prefix)] = value
+	}
+	return values, nextPageToken, nil
+}
@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirSessionStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	scope := sessions.WithSyncID("issues")
+
+	store, err := newDirSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string][]byte)
+	for i := range sessions.MaxKeysPerRequest + 5 {
+		values[fmt.Sprintf("issue/%03d", i)] = []byte(fmt.Sprintf(`{"id":%d}`, i))
+	}
+	if err := store.SetMany(ctx, values, scope); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(ctx, "watermark", []byte(`{}`), scope); err != nil {
+		t.Fatal(err)
+	}
+
+	// Values are read back by a new store on the same directory.
+	store, err = newDirSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, found, err := store.Get(ctx, "issue/007", scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, found)
+	assert.Equal(t, `{"id":7}`, string(value))
+
+	_, found, err = store.Get(ctx, "issue/007")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, found, "values are kept per scope")
+
+	t.Run("get all pages through prefixed keys", func(t *testing.T) {
+		got := make(map[string][]byte)
+		pages := 0
+		pageToken := ""
+		for {
+			page, next, err := store.GetAll(ctx, pageToken, scope, sessions.WithPrefix("issue/"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range page {
+				got["issue/"+key] = value
+			}
+			pages++
+			if next == "" {
+				break
+			}
+			pageToken = next
+		}
+		assert.Equal(t, 2, pages)
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("delete and clear", func(t *testing.T) {
+		if err := store.Delete(ctx, "issue/007", scope); err != nil {
+			t.Fatal(err)
+		}
+		_, found, err := store.Get(ctx, "issue/007", scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, found)
+		assert.NoError(t, store.Delete(ctx, "issue/007", scope), "deleting a missing value is a no-op")
+
+		if err := store.Clear(ctx, scope); err != nil {
+			t.Fatal(err)
+		}
+		all, next, err := store.GetAll(ctx, "", scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, all)
+		assert.Empty(t, next)
+	})
+}
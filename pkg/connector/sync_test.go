@@ -0,0 +1,223 @@
+package connector
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/cli"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/dotc1z"
+	sdkSync "github.com/conductorone/baton-sdk/pkg/sync"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	cfg "github.com/conductorone/baton-wiz-win/pkg/config"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// connectorClient exposes an in-process connector server over a loopback gRPC connection, as the SDK runner does.
+type connectorClient struct {
+	v2.ResourceTypesServiceClient
+	v2.ResourcesServiceClient
+	v2.EntitlementsServiceClient
+	v2.GrantsServiceClient
+	v2.ConnectorServiceClient
+	v2.AssetServiceClient
+	v2.GrantManagerServiceClient
+	v2.ResourceManagerServiceClient
+	v2.ResourceDeleterServiceClient
+	v2.AccountManagerServiceClient
+	v2.CredentialManagerServiceClient
+	v2.EventServiceClient
+	v2.TicketsServiceClient
+	v2.ActionServiceClient
+	v2.ResourceGetterServiceClient
+}
+
+var _ types.ConnectorClient = (*connectorClient)(nil)
+
+// newConnectorClient builds the connector against a fake Wiz server and serves it over gRPC.
+func newConnectorClient(t *testing.T, server *wiztest.Server) types.ConnectorClient {
+	t.Helper()
+	ctx := context.Background()
+
+	cb, _, err := New(ctx, &cfg.WizWin{
+		WizApiUrl:       server.APIURL,
+		WizClientId:     server.ClientID,
+		WizClientSecret: server.ClientSecret,
+		WizAuthEndpoint: server.TokenURL,
+	}, &cli.ConnectorOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := connectorbuilder.NewConnector(ctx, cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grpcServer := grpc.NewServer()
+	v2.RegisterResourceTypesServiceServer(grpcServer, srv)
+	v2.RegisterResourcesServiceServer(grpcServer, srv)
+	v2.RegisterEntitlementsServiceServer(grpcServer, srv)
+	v2.RegisterGrantsServiceServer(grpcServer, srv)
+	v2.RegisterConnectorServiceServer(grpcServer, srv)
+	v2.RegisterAssetServiceServer(grpcServer, srv)
+	v2.RegisterGrantManagerServiceServer(grpcServer, srv)
+	v2.RegisterResourceManagerServiceServer(grpcServer, srv)
+	v2.RegisterResourceDeleterServiceServer(grpcServer, srv)
+	v2.RegisterAccountManagerServiceServer(grpcServer, srv)
+	v2.RegisterCredentialManagerServiceServer(grpcServer, srv)
+	v2.RegisterEventServiceServer(grpcServer, srv)
+	v2.RegisterTicketsServiceServer(grpcServer, srv)
+	v2.RegisterActionServiceServer(grpcServer, srv)
+	v2.RegisterResourceGetterServiceServer(grpcServer, srv)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &connectorClient{
+		ResourceTypesServiceClient:     v2.NewResourceTypesServiceClient(conn),
+		ResourcesServiceClient:         v2.NewResourcesServiceClient(conn),
+		EntitlementsServiceClient:      v2.NewEntitlementsServiceClient(conn),
+		GrantsServiceClient:            v2.NewGrantsServiceClient(conn),
+		ConnectorServiceClient:         v2.NewConnectorServiceClient(conn),
+		AssetServiceClient:             v2.NewAssetServiceClient(conn),
+		GrantManagerServiceClient:      v2.NewGrantManagerServiceClient(conn),
+		ResourceManagerServiceClient:   v2.NewResourceManagerServiceClient(conn),
+		ResourceDeleterServiceClient:   v2.NewResourceDeleterServiceClient(conn),
+		AccountManagerServiceClient:    v2.NewAccountManagerServiceClient(conn),
+		CredentialManagerServiceClient: v2.NewCredentialManagerServiceClient(conn),
+		EventServiceClient:             v2.NewEventServiceClient(conn),
+		TicketsServiceClient:           v2.NewTicketsServiceClient(conn),
+		ActionServiceClient:            v2.NewActionServiceClient(conn),
+		ResourceGetterServiceClient:    v2.NewResourceGetterServiceClient(conn),
+	}
+}
+
+// runSync performs a full sync into a c1z file and returns the file opened for reading.
+func runSync(t *testing.T, client types.ConnectorClient) *dotc1z.C1File {
+	t.Helper()
+	ctx := context.Background()
+
+	tmpDir := t.TempDir()
+	c1zPath := filepath.Join(tmpDir, "sync.c1z")
+
+	syncer, err := sdkSync.NewSyncer(ctx, client, sdkSync.WithC1ZPath(c1zPath), sdkSync.WithTmpDir(tmpDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := dotc1z.NewC1ZFile(ctx, c1zPath, dotc1z.WithTmpDir(tmpDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close(ctx) })
+
+	return store
+}
+
+func listAllResources(t *testing.T, store *dotc1z.C1File, resourceTypeID string) []*v2.Resource {
+	t.Helper()
+	ctx := context.Background()
+
+	var resources []*v2.Resource
+	pageToken := ""
+	for {
+		resp, err := store.ListResources(ctx, &v2.ResourcesServiceListResourcesRequest{
+			ResourceTypeId: resourceTypeID,
+			PageToken:      pageToken,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources = append(resources, resp.GetList()...)
+		pageToken = resp.GetNextPageToken()
+		if pageToken == "" {
+			return resources
+		}
+	}
+}
+
+// listAllGrants returns every synced grant as "entitlement ID -> principal ID" pairs.
+func listAllGrants(t *testing.T, store *dotc1z.C1File) map[string][]string {
+	t.Helper()
+	ctx := context.Background()
+
+	grants := make(map[string][]string)
+	pageToken := ""
+	for {
+		resp, err := store.ListGrants(ctx, &v2.GrantsServiceListGrantsRequest{PageToken: pageToken})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, g := range resp.GetList() {
+			entitlementID := g.GetEntitlement().GetId()
+			grants[entitlementID] = append(grants[entitlementID], g.GetPrincipal().GetId().GetResource())
+		}
+		pageToken = resp.GetNextPageToken()
+		if pageToken == "" {
+			return grants
+		}
+	}
+}
+
+func TestSyncAgainstFakeWiz(t *testing.T) {
+	// A page size of one forces every list call through Relay pagination.
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures(), wiztest.WithPageSize(1))
+	// Transient failures are retried by the syncer without failing the sync.
+	server.InjectFault("ListUsers", wiztest.Fault{StatusCode: 503, Times: 1})
+
+	store := runSync(t, newConnectorClient(t, server))
+
+	assert.Len(t, listAllResources(t, store, userResourceType.Id), 3)
+	assert.Len(t, listAllResources(t, store, roleResourceType.Id), 3)
+	assert.Len(t, listAllResources(t, store, projectResourceType.Id), 2)
+	assert.Len(t, listAllResources(t, store, projectRoleResourceType.Id), 2)
+	assert.Len(t, listAllResources(t, store, serviceAccountResourceType.Id), 2)
+	assert.Len(t, listAllResources(t, store, permissionResourceType.Id), 5)
+	// Each issue is synced as an issue insight and a risk score insight.
+	assert.Len(t, listAllResources(t, store, securityInsightResourceType.Id), 6)
+	assert.Len(t, listAllResources(t, store, cloudAccountResourceType.Id), 3)
+	assert.Len(t, listAllResources(t, store, samlGroupMappingResourceType.Id), 2)
+
+	grants := listAllGrants(t, store)
+	assert.ElementsMatch(t, []string{"alice@example.com", "idp-okta:wiz-admins"}, grants["role:GLOBAL_ADMIN:member"])
+	assert.ElementsMatch(t, []string{"alice@example.com"}, grants["project:project-1:owner"])
+	assert.ElementsMatch(t, []string{"bob@example.com"}, grants["project:project-1:champion"])
+	assert.ElementsMatch(t, []string{"bob@example.com", "carol@example.com", "idp-okta:payments-engineers"}, grants["project:project-1:member"])
+	assert.ElementsMatch(t, []string{"bob@example.com", "idp-okta:payments-engineers"}, grants["project-role:project-1:PROJECT_MEMBER:member"])
+	assert.Empty(t, grants["project-role:project-2:PROJECT_MEMBER:member"])
+	// Role permissions are expanded to the role's members, including mapped SAML groups.
+	assert.ElementsMatch(t,
+		[]string{"sa-1", "sa-2", "PROJECT_MEMBER", "bob@example.com", "idp-okta:payments-engineers"},
+		grants["permission:read:issues:assigned"],
+	)
+	assert.ElementsMatch(t, []string{"GLOBAL_ADMIN", "alice@example.com", "idp-okta:wiz-admins"}, grants["permission:admin:all:assigned"])
+	// Cloud account access is granted to projects and expanded to the principals holding a project entitlement.
+	assert.ElementsMatch(t,
+		[]string{"project-1", "project-2", "alice@example.com", "bob@example.com", "carol@example.com", "idp-okta:payments-engineers"},
+		grants["cloud-account:cloud-account-2:access"],
+	)
+
+	assert.Greater(t, server.Calls("ListUsers"), 3)
+}
@@ -5,13 +5,19 @@ import (
 	"fmt"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type userBuilder struct {
 	client wiz.Client
+	// expandProjectRoles grants the per-project role entitlements added by projectBuilder.StaticEntitlements.
+	expandProjectRoles bool
 }
 
 func (u *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -40,35 +46,7 @@ func (u *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 			continue
 		}
 
-		// Store role ID and project IDs in profile for use in Grants()
-		// This avoids having to query all users again when generating grants
-		profile := make(map[string]interface{})
-		if user.EffectiveRole.ID != "" {
-			profile["role_id"] = user.EffectiveRole.ID
-		}
-
-		projectIDs := make([]interface{}, 0, len(user.EffectiveAssignedProjects))
-		for _, project := range user.EffectiveAssignedProjects {
-			projectIDs = append(projectIDs, project.ID)
-		}
-		if len(projectIDs) > 0 {
-			profile["project_ids"] = projectIDs
-		}
-
-		// Use email as the resource ID instead of the Wiz user ID because:
-		// - userAccounts and users endpoints return different IDs for the same person
-		// - Email is consistent across all Wiz API endpoints
-		// - Project grants reference users by email
-		userResource, err := resource.NewUserResource(
-			user.Email,
-			userResourceType,
-			user.Email, // Use email as ID for consistency
-			[]resource.UserTraitOption{
-				resource.WithEmail(user.Email, true),
-				resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
-				resource.WithUserProfile(profile),
-			},
-		)
+		userResource, err := newUserResource(&user)
 		if err != nil {
 			return nil, nil, fmt.Errorf("wiz-connector: failed to create user resource: %w", err)
 		}
@@ -85,12 +63,30 @@ func (u *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 	return users, syncResults, nil
 }
 
+// Get returns a single user by resource ID (the user's email), e.g. to refresh it after provisioning without a full sync.
+func (u *userBuilder) Get(ctx context.Context, resourceId *v2.ResourceId, parentResourceId *v2.ResourceId) (*v2.Resource, annotations.Annotations, error) {
+	email := resourceId.GetResource()
+
+	user, err := u.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	userResource, err := newUserResource(user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wiz-connector: failed to create user resource: %w", err)
+	}
+
+	return userResource, nil, nil
+}
+
 // Entitlements returns an empty slice for users as they don't have child entitlements.
 func (u *userBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ resource.SyncOpAttrs) ([]*v2.Entitlement, *resource.SyncOpResults, error) {
 	return nil, nil, nil
 }
 
 // Grants returns grants for projects this user is a member of and their role assignment.
+// Project-scoped roles are also granted on the role's binding to each of the user's projects.
 // Data is read from the user profile that was populated during List().
 func (u *userBuilder) Grants(ctx context.Context, res *v2.Resource, attr resource.SyncOpAttrs) ([]*v2.Grant, *resource.SyncOpResults, error) {
 	var grants []*v2.Grant
@@ -107,12 +103,15 @@ func (u *userBuilder) Grants(ctx context.Context, res *v2.Resource, attr resourc
 		return grants, nil, nil
 	}
 
+	roleID := profile.Fields["role_id"].GetStringValue()
+	roleProjectScoped := profile.Fields["role_project_scoped"].GetBoolValue()
+
 	// Create role grant if role_id is present
-	if roleID, ok := profile.Fields["role_id"]; ok && roleID.GetStringValue() != "" {
+	if roleID != "" {
 		roleResource, err := resource.NewRoleResource(
 			"", // Name is not needed for grant creation
 			roleResourceType,
-			roleID.GetStringValue(),
+			roleID,
 			[]resource.RoleTraitOption{},
 		)
 		if err != nil {
@@ -123,6 +122,16 @@ func (u *userBuilder) Grants(ctx context.Context, res *v2.Resource, attr resourc
 		grants = append(grants, roleGrant)
 	}
 
+	// Project members act with the user's role inside the project, so member grants carry it as metadata
+	var memberGrantOpts []grant.GrantOption
+	if roleID != "" {
+		memberGrantOpts = append(memberGrantOpts, grant.WithGrantMetadata(map[string]interface{}{
+			"role_id":     roleID,
+			"role_name":   profile.Fields["role_name"].GetStringValue(),
+			"role_scopes": toInterfaceSlice(profileStrings(profile.Fields["role_scopes"])),
+		}))
+	}
+
 	// Create project grants if project_ids is present
 	if projectIDsValue, ok := profile.Fields["project_ids"]; ok {
 		projectIDsList := projectIDsValue.GetListValue()
@@ -143,15 +152,176 @@ func (u *userBuilder) Grants(ctx context.Context, res *v2.Resource, attr resourc
 					return nil, nil, fmt.Errorf("wiz-connector: failed to create project resource: %w", err)
 				}
 
-				projectGrant := grant.NewGrant(projectRes, "member", res.Id)
+				projectGrant := grant.NewGrant(projectRes, "member", res.Id, memberGrantOpts...)
 				grants = append(grants, projectGrant)
+
+				if u.expandProjectRoles && roleProjectScoped {
+					grants = append(grants, grant.NewGrant(projectRes, roleID, res.Id))
+				}
 			}
 		}
 	}
 
+	// Project-scoped roles only apply within the user's projects, so also grant the role scoped to each of them
+	if roleProjectScoped {
+		for _, projectID := range profileStrings(profile.Fields["project_ids"]) {
+			projectRoleRes, err := newProjectRoleResource(roleID, "", &v2.ResourceId{
+				ResourceType: projectResourceType.Id,
+				Resource:     projectID,
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("wiz-connector: failed to create project role resource: %w", err)
+			}
+
+			grants = append(grants, grant.NewGrant(projectRoleRes, "member", res.Id))
+		}
+	}
+
 	return grants, nil, nil
 }
 
-func newUserBuilder(client wiz.Client) *userBuilder {
-	return &userBuilder{client: client}
+// CreateAccount creates a Wiz user from the account creation schema and sends them an email invite.
+func (u *userBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	credentialOptions *v2.LocalCredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	profile := accountInfo.GetProfile()
+
+	email, _ := resource.GetProfileStringValue(profile, "email")
+	if email == "" {
+		for _, e := range accountInfo.GetEmails() {
+			if e.GetIsPrimary() || email == "" {
+				email = e.GetAddress()
+			}
+		}
+	}
+	if email == "" {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: email is required to create a user")
+	}
+
+	name, _ := resource.GetProfileStringValue(profile, "name")
+	if name == "" {
+		name = email
+	}
+
+	roleID, _ := resource.GetProfileStringValue(profile, "role_id")
+	if roleID == "" {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, "wiz-connector: role_id is required to create a user")
+	}
+
+	var projectIDs []string
+	if projectIDsValue, ok := profile.GetFields()["project_ids"]; ok {
+		projectIDs = profileStrings(projectIDsValue)
+	}
+
+	user, err := u.client.CreateUser(ctx, wiz.CreateUserInput{
+		Name:               name,
+		Email:              email,
+		Role:               roleID,
+		AssignedProjectIDs: projectIDs,
+		SendEmailInvite:    true,
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("wiz-connector: failed to create user %s: %w", email, err)
+	}
+
+	userResource, err := newUserResource(user)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("wiz-connector: failed to create user resource: %w", err)
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource:              userResource,
+		IsCreateAccountResult: true,
+	}, nil, nil, nil
+}
+
+// CreateAccountCapabilityDetails reports that Wiz users are created without a password; they sign in through the email invite or SSO.
+func (u *userBuilder) CreateAccountCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return &v2.CredentialDetailsAccountProvisioning{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+	}, nil, nil
+}
+
+// Delete removes the Wiz user identified by the resource ID (the user's email).
+func (u *userBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId, parentResourceID *v2.ResourceId) (annotations.Annotations, error) {
+	email := resourceId.GetResource()
+
+	user, err := u.client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to get user %s: %w", email, err)
+	}
+
+	if err := u.client.DeleteUser(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("wiz-connector: failed to delete user %s: %w", email, err)
+	}
+
+	return nil, nil
+}
+
+// newUserResource creates a user resource, storing role details and project IDs in the profile for use in Grants().
+// This avoids having to query all users again when generating grants.
+func newUserResource(user *wiz.User) (*v2.Resource, error) {
+	profile := make(map[string]interface{})
+	if user.EffectiveRole.ID != "" {
+		profile["role_id"] = user.EffectiveRole.ID
+		profile["role_name"] = user.EffectiveRole.Name
+		profile["role_scopes"] = toInterfaceSlice(user.EffectiveRole.Scopes)
+		profile["role_project_scoped"] = user.EffectiveRole.IsProjectScoped
+	}
+
+	if projectIDs := activeProjectIDs(user.EffectiveAssignedProjects); len(projectIDs) > 0 {
+		profile["project_ids"] = toInterfaceSlice(projectIDs)
+	}
+
+	profile["is_analytics_enabled"] = user.IsAnalyticsEnabled
+	if user.IdentityProviderType != "" {
+		profile["identity_provider_type"] = user.IdentityProviderType
+	}
+	if user.IdentityProvider != nil && user.IdentityProvider.Name != "" {
+		profile["identity_provider"] = user.IdentityProvider.Name
+	}
+
+	traitOpts := []resource.UserTraitOption{
+		resource.WithEmail(user.Email, true),
+		resource.WithUserLogin(user.Email),
+		resource.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_HUMAN),
+		resource.WithUserProfile(profile),
+		// Users signing in through a SAML identity provider are SSO users; local Wiz accounts are not
+		resource.WithSSOStatus(&v2.UserTrait_SSOStatus{SsoEnabled: user.IdentityProviderType == "SAML" || user.IdentityProvider != nil}),
+	}
+
+	if user.IsSuspended {
+		traitOpts = append(traitOpts, resource.WithDetailedStatus(v2.UserTrait_Status_STATUS_DISABLED, "suspended"))
+	} else {
+		traitOpts = append(traitOpts, resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED))
+	}
+	if user.CreatedAt != nil {
+		traitOpts = append(traitOpts, resource.WithCreatedAt(*user.CreatedAt))
+	}
+	if user.LastLoginAt != nil {
+		traitOpts = append(traitOpts, resource.WithLastLogin(*user.LastLoginAt))
+	}
+
+	// Use email as the resource ID instead of the Wiz user ID because:
+	// - userAccounts and users endpoints return different IDs for the same person
+	// - Email is consistent across all Wiz API endpoints
+	// - Project grants reference users by email
+	return resource.NewUserResource(
+		user.Email,
+		userResourceType,
+		user.Email, // Use email as ID for consistency
+		traitOpts,
+	)
+}
+
+func newUserBuilder(client wiz.Client, expandProjectRoles bool) *userBuilder {
+	return &userBuilder{
+		client:             client,
+		expandProjectRoles: expandProjectRoles,
+	}
 }
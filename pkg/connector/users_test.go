@@ -0,0 +1,246 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUserCreateAccount(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	builder := newUserBuilder(client, false)
+
+	profile, err := structpb.NewStruct(map[string]interface{}{
+		"email":       "new.user@example.com",
+		"name":        "New User",
+		"role_id":     "PROJECT_READER",
+		"project_ids": []interface{}{"project-1", "project-2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, _, _, err := builder.CreateAccount(ctx, &v2.AccountInfo{Profile: profile}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, ok := resp.(*v2.CreateAccountResponse_SuccessResult)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.True(t, result.GetIsCreateAccountResult())
+	assert.Equal(t, "new.user@example.com", result.GetResource().GetId().GetResource())
+
+	if assert.Len(t, client.users, 1) {
+		assert.Equal(t, "New User", client.users[0].Name)
+		assert.Equal(t, "PROJECT_READER", client.users[0].EffectiveRole.ID)
+		assert.Equal(t, []wiz.ProjectRef{{ID: "project-1"}, {ID: "project-2"}}, client.users[0].AssignedProjects)
+	}
+}
+
+func TestUserCreateAccountRequiresRole(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	builder := newUserBuilder(client, false)
+
+	profile, err := structpb.NewStruct(map[string]interface{}{"email": "new.user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err = builder.CreateAccount(ctx, &v2.AccountInfo{Profile: profile}, nil)
+	assert.Error(t, err)
+	assert.Zero(t, client.callCount("CreateUser"))
+}
+
+func TestUserDelete(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	client.users = []wiz.User{{ID: "u1", Email: "old.user@example.com"}}
+	builder := newUserBuilder(client, false)
+
+	_, err := builder.Delete(ctx, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "old.user@example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, client.users)
+}
+
+func TestUserResourceStatusAndLogin(t *testing.T) {
+	createdAt := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	lastLoginAt := time.Date(2025, 8, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		user       wiz.User
+		wantStatus v2.UserTrait_Status_Status
+		wantSSO    bool
+		wantLogin  *time.Time
+	}{
+		{
+			name: "active sso user",
+			user: wiz.User{
+				Email:                "active@example.com",
+				CreatedAt:            &createdAt,
+				LastLoginAt:          &lastLoginAt,
+				IdentityProviderType: "SAML",
+				IdentityProvider:     &wiz.IdentityProviderRef{ID: "idp-1", Name: "Okta"},
+			},
+			wantStatus: v2.UserTrait_Status_STATUS_ENABLED,
+			wantSSO:    true,
+			wantLogin:  &lastLoginAt,
+		},
+		{
+			name: "suspended local user that never logged in",
+			user: wiz.User{
+				Email:                "suspended@example.com",
+				IsSuspended:          true,
+				CreatedAt:            &createdAt,
+				IdentityProviderType: "WIZ",
+			},
+			wantStatus: v2.UserTrait_Status_STATUS_DISABLED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := newUserResource(&tt.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			userTrait, err := resource.GetUserTrait(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wantStatus, userTrait.GetStatus().GetStatus())
+			assert.Equal(t, tt.wantSSO, userTrait.GetSsoStatus().GetSsoEnabled())
+			assert.Equal(t, tt.user.Email, userTrait.GetLogin())
+			assert.Equal(t, v2.UserTrait_ACCOUNT_TYPE_HUMAN, userTrait.GetAccountType())
+			assert.Equal(t, createdAt, userTrait.GetCreatedAt().AsTime())
+			if tt.wantLogin != nil {
+				assert.Equal(t, *tt.wantLogin, userTrait.GetLastLogin().AsTime())
+			} else {
+				assert.Nil(t, userTrait.GetLastLogin())
+			}
+		})
+	}
+}
+
+func TestUserProjectGrantsCarryRole(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeClient()
+	client.roles = []wiz.UserRole{
+		{ID: "GLOBAL_READER", Name: "Global Reader"},
+		{ID: "PROJECT_ADMIN", Name: "Project Admin", IsProjectScoped: true},
+	}
+
+	userResource, err := newUserResource(&wiz.User{
+		Email:                     "dana@example.com",
+		EffectiveRole:             wiz.UserRoleRef{ID: "PROJECT_ADMIN", Name: "Project Admin", Scopes: []string{"read:all", "write:projects"}, IsProjectScoped: true},
+		EffectiveAssignedProjects: []wiz.ProjectRef{{ID: "project-1", Name: "Payments"}, {ID: "project-2", Name: "Legacy", Archived: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("member grants carry the role", func(t *testing.T) {
+		grants, _, err := newUserBuilder(client, false).Grants(ctx, userResource, resource.SyncOpAttrs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var memberGrant *v2.Grant
+		for _, g := range grants {
+			if g.GetEntitlement().GetId() == "project:project-1:member" {
+				memberGrant = g
+			}
+			assert.NotEqual(t, "project:project-1:PROJECT_ADMIN", g.GetEntitlement().GetId())
+		}
+		if memberGrant == nil {
+			t.Fatal("missing project member grant")
+		}
+
+		metadata := &v2.GrantMetadata{}
+		annos := annotations.Annotations(memberGrant.GetAnnotations())
+		ok, err := annos.Pick(metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, ok)
+		assert.Equal(t, map[string]interface{}{
+			"role_id":     "PROJECT_ADMIN",
+			"role_name":   "Project Admin",
+			"role_scopes": []interface{}{"read:all", "write:projects"},
+		}, metadata.GetMetadata().AsMap())
+	})
+
+	t.Run("project roles are expanded into entitlements", func(t *testing.T) {
+		entitlements, _, err := newProjectBuilder(client, true).StaticEntitlements(ctx, resource.SyncOpAttrs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var slugs []string
+		for _, e := range entitlements {
+			slugs = append(slugs, e.GetSlug())
+		}
+		assert.Equal(t, []string{"owner", "champion", "member", "PROJECT_ADMIN"}, slugs)
+		immutable := &v2.EntitlementImmutable{}
+		annos := annotations.Annotations(entitlements[3].GetAnnotations())
+		assert.True(t, annos.Contains(immutable))
+
+		grants, _, err := newUserBuilder(client, true).Grants(ctx, userResource, resource.SyncOpAttrs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entitlementIDs []string
+		for _, g := range grants {
+			entitlementIDs = append(entitlementIDs, g.GetEntitlement().GetId())
+		}
+		assert.Contains(t, entitlementIDs, "project:project-1:PROJECT_ADMIN")
+	})
+
+	t.Run("archived projects are not granted", func(t *testing.T) {
+		grants, _, err := newUserBuilder(client, true).Grants(ctx, userResource, resource.SyncOpAttrs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, g := range grants {
+			assert.NotContains(t, g.GetEntitlement().GetId(), "project-2")
+			assert.NotEqual(t, "project-2", g.GetEntitlement().GetResource().GetParentResourceId().GetResource())
+		}
+	})
+}
+
+func TestUserGet(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	client.users = []wiz.User{{
+		ID:            "u1",
+		Email:         "alice@example.com",
+		EffectiveRole: wiz.UserRoleRef{ID: "GLOBAL_READER", Name: "Global Reader"},
+	}}
+	builder := newUserBuilder(client, false)
+
+	res, _, err := builder.Get(ctx, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "alice@example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "alice@example.com", res.GetId().GetResource())
+	userTrait, err := resource.GetUserTrait(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "GLOBAL_READER", userTrait.GetProfile().GetFields()["role_id"].GetStringValue())
+	assert.Equal(t, 0, client.callCount("ListUsers"))
+}
@@ -2,11 +2,17 @@ package wiz
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"google.golang.org/grpc/codes"
@@ -18,7 +24,29 @@ type Client interface {
 	ListUsers(ctx context.Context, cursor *string) (*UserConnection, error)
 	ListProjects(ctx context.Context, cursor *string) (*ProjectConnection, error)
 	ListUserRoles(ctx context.Context, cursor *string) (*UserRoleConnection, error)
-	ListIssues(ctx context.Context, cursor *string) (*IssueConnection, error)
+	GetRole(ctx context.Context, roleID string) (*UserRole, error)
+	ListIssues(ctx context.Context, filter IssueFilter, cursor *string) (*IssueConnection, error)
+	UpdateIssue(ctx context.Context, issueID string, patch UpdateIssuePatch) (*Issue, error)
+	CreateIssueNote(ctx context.Context, issueID, text string) (*IssueNote, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	UpdateUser(ctx context.Context, userID string, patch UpdateUserPatch) error
+	GetProject(ctx context.Context, projectID string) (*Project, error)
+	UpdateProject(ctx context.Context, projectID string, patch UpdateProjectPatch) error
+	CreateProject(ctx context.Context, input CreateProjectInput) (*Project, error)
+	CreateUser(ctx context.Context, input CreateUserInput) (*User, error)
+	DeleteUser(ctx context.Context, userID string) error
+	CreateUserRole(ctx context.Context, input CreateUserRoleInput) (*UserRole, error)
+	UpdateUserRole(ctx context.Context, roleID string, patch UpdateUserRolePatch) (*UserRole, error)
+	DeleteUserRole(ctx context.Context, roleID string) error
+	ListServiceAccounts(ctx context.Context, cursor *string) (*ServiceAccountConnection, error)
+	RotateServiceAccountSecret(ctx context.Context, serviceAccountID string) (*ServiceAccountCredentials, error)
+	ListCloudAccounts(ctx context.Context, cursor *string) (*CloudAccountConnection, error)
+	ListSAMLIdentityProviders(ctx context.Context, cursor *string) (*SAMLIdentityProviderConnection, error)
+	ListAuditLogEntries(ctx context.Context, since time.Time, cursor *string) (*AuditLogEntryConnection, error)
+	GetCloudAccount(ctx context.Context, cloudAccountID string) (*CloudAccount, error)
+	RequestConnectorScan(ctx context.Context, connectorID string) error
+	GetReport(ctx context.Context, reportID string) (*Report, error)
+	RerunReport(ctx context.Context, reportID string) (*Report, error)
 }
 
 // client implements the Client interface.
@@ -58,6 +86,7 @@ func NewClient(ctx context.Context, apiURL, clientID, clientSecret, authEndpoint
 
 // graphQLRequest makes a GraphQL request to the Wiz API using baton-sdk's HTTP wrapper.
 // The wrapper handles retries, rate limiting, and error wrapping automatically.
+// GraphQL errors are returned as *GraphQLError values, which carry the matching gRPC code.
 func (c *client) graphQLRequest(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
 	requestBody := map[string]interface{}{
 		"query":     query,
@@ -77,7 +106,6 @@ func (c *client) graphQLRequest(ctx context.Context, query string, variables map
 
 	// Use a temporary struct to capture the GraphQL response envelope
 	var gqlResp graphQLResponse
-	gqlResp.Data = result
 
 	// Execute the request with JSON response handling
 	resp, err := c.wrapper.Do(req, uhttp.WithJSONResponse(&gqlResp))
@@ -88,9 +116,23 @@ func (c *client) graphQLRequest(ctx context.Context, query string, variables map
 		defer resp.Body.Close()
 	}
 
+	hasData := len(gqlResp.Data) > 0 && string(gqlResp.Data) != "null"
+	if hasData {
+		if err := json.Unmarshal(gqlResp.Data, result); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+
 	// Check for GraphQL-specific errors in the response
 	if len(gqlResp.Errors) > 0 {
-		return status.Errorf(codes.Unknown, "graphql errors: %+v", gqlResp.Errors)
+		// Partial responses whose only errors are dangling references are usable as-is
+		if hasData && isPartialNotFound(gqlResp.Errors) {
+			ctxzap.Extract(ctx).Warn("wiz-connector: ignoring not found errors in partial graphql response",
+				zap.Error(joinGraphQLErrors(gqlResp.Errors)),
+			)
+			return nil
+		}
+		return joinGraphQLErrors(gqlResp.Errors)
 	}
 
 	return nil
@@ -106,13 +148,25 @@ func (c *client) ListUsers(ctx context.Context, cursor *string) (*UserConnection
 					id
 					name
 					email
+					isSuspended
+					isAnalyticsEnabled
+					createdAt
+					lastLoginAt
+					identityProviderType
+					identityProvider {
+						id
+						name
+					}
 					effectiveRole {
 						id
 						name
+						scopes
+						isProjectScoped
 					}
 					effectiveAssignedProjects {
 						id
 						name
+						archived
 					}
 				}
 				pageInfo {
@@ -149,6 +203,8 @@ func (c *client) ListProjects(ctx context.Context, cursor *string) (*ProjectConn
 					id
 					name
 					description
+					businessUnit
+					archived
 					projectOwners {
 						id
 						email
@@ -215,26 +271,51 @@ func (c *client) ListUserRoles(ctx context.Context, cursor *string) (*UserRoleCo
 	return connection, nil
 }
 
-// ListIssues retrieves a paginated list of security issues from Wiz.
-// Only returns issues affecting USER_ACCOUNT or SERVICE_ACCOUNT entities (server-side filtered)
-// to focus on IAM-relevant security risks rather than infrastructure issues.
-func (c *client) ListIssues(ctx context.Context, cursor *string) (*IssueConnection, error) {
+// GetRole retrieves a single user role from Wiz by ID.
+// userRolesV2 returns every role in one response and cannot be filtered by ID, so the role is picked from the full list.
+func (c *client) GetRole(ctx context.Context, roleID string) (*UserRole, error) {
+	roles, err := c.ListUserRoles(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	for _, role := range roles.Nodes {
+		if role.ID == roleID {
+			return &role, nil
+		}
+	}
+
+	return nil, status.Errorf(codes.NotFound, "wiz role %s not found", roleID)
+}
+
+// ListIssues retrieves a paginated list of security issues from Wiz matching filter.
+// Filtering happens server-side through the issues filterBy argument.
+func (c *client) ListIssues(ctx context.Context, filter IssueFilter, cursor *string) (*IssueConnection, error) {
 	query := `
-		query ListIssues($cursor: String) {
-			issues(first: 100, after: $cursor, filterBy: {
-				status: [OPEN, IN_PROGRESS],
-				relatedEntity: {
-					type: [USER_ACCOUNT, SERVICE_ACCOUNT]
-				}
-			}) {
+		query ListIssues($cursor: String, $filterBy: IssueFilters) {
+			issues(first: 100, after: $cursor, filterBy: $filterBy) {
 				nodes {
 					id
 					type
 					severity
 					status
 					createdAt
+					updatedAt
+					dueAt
+					resolvedAt
 					sourceRule {
+						id
 						name
+						... on Control {
+							controlDescription
+							resolutionRecommendation
+							securitySubCategories {
+								title
+								category {
+									name
+								}
+							}
+						}
 					}
 					entitySnapshot {
 						id
@@ -243,6 +324,35 @@ func (c *client) ListIssues(ctx context.Context, cursor *string) (*IssueConnecti
 						type
 						name
 					}
+					projects {
+						id
+						name
+						businessUnit
+						riskProfile {
+							businessImpact
+						}
+					}
+					serviceTickets {
+						externalId
+						name
+						url
+					}
+					notes {
+						createdAt
+						text
+						user {
+							id
+							email
+						}
+						serviceAccount {
+							id
+							name
+						}
+					}
+					evidence {
+						name
+						description
+					}
 				}
 				pageInfo {
 					hasNextPage
@@ -252,7 +362,14 @@ func (c *client) ListIssues(ctx context.Context, cursor *string) (*IssueConnecti
 		}
 	`
 
-	variables := map[string]interface{}{}
+	filterBy, err := filter.filterBy()
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]interface{}{
+		"filterBy": filterBy,
+	}
 	if cursor != nil && *cursor != "" {
 		variables["cursor"] = *cursor
 	}
@@ -264,3 +381,909 @@ func (c *client) ListIssues(ctx context.Context, cursor *string) (*IssueConnecti
 
 	return &result.Issues, nil
 }
+
+// UpdateIssue changes the status or due date of an issue using the updateIssue mutation.
+// Only the issue's ID, status and dates are returned. Requires the write:issues permission.
+func (c *client) UpdateIssue(ctx context.Context, issueID string, patch UpdateIssuePatch) (*Issue, error) {
+	query := `
+		mutation UpdateIssue($input: UpdateIssueInput!) {
+			updateIssue(input: $input) {
+				issue {
+					id
+					status
+					createdAt
+					updatedAt
+					dueAt
+					resolvedAt
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":    issueID,
+			"patch": patch,
+		},
+	}
+
+	var result struct {
+		UpdateIssue struct {
+			Issue Issue `json:"issue"`
+		} `json:"updateIssue"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to update issue: %w", err)
+	}
+
+	return &result.UpdateIssue.Issue, nil
+}
+
+// CreateIssueNote adds a note to an issue using the createIssueNote mutation.
+// Requires the write:issues permission.
+func (c *client) CreateIssueNote(ctx context.Context, issueID, text string) (*IssueNote, error) {
+	query := `
+		mutation CreateIssueNote($input: CreateIssueNoteInput!) {
+			createIssueNote(input: $input) {
+				issueNote {
+					createdAt
+					text
+					user {
+						id
+						email
+					}
+					serviceAccount {
+						id
+						name
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"issueId": issueID,
+			"text":    text,
+		},
+	}
+
+	var result struct {
+		CreateIssueNote struct {
+			IssueNote IssueNote `json:"issueNote"`
+		} `json:"createIssueNote"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to create issue note: %w", err)
+	}
+
+	return &result.CreateIssueNote.IssueNote, nil
+}
+
+// Validate checks that every filter value is an allowed Wiz enum value.
+func (f IssueFilter) Validate() error {
+	if err := validateEnum("status", f.Statuses, IssueStatuses); err != nil {
+		return err
+	}
+	if err := validateEnum("entity type", f.EntityTypes, IssueEntityTypes); err != nil {
+		return err
+	}
+	if err := validateEnum("issue type", f.Types, IssueTypes); err != nil {
+		return err
+	}
+	if f.MinSeverity != "" {
+		return validateEnum("severity", []string{f.MinSeverity}, IssueSeverities)
+	}
+	return nil
+}
+
+func validateEnum(name string, values []string, allowed []string) error {
+	for _, value := range values {
+		if !slices.Contains(allowed, value) {
+			return status.Errorf(codes.InvalidArgument, "invalid issue %s %q, must be one of %s", name, value, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// filterBy translates the filter into the GraphQL IssueFilters input.
+func (f IssueFilter) filterBy() (map[string]interface{}, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	filterBy := map[string]interface{}{}
+	if len(f.Statuses) > 0 {
+		filterBy["status"] = f.Statuses
+	}
+	if f.MinSeverity != "" {
+		// Wiz filters on an explicit list of severities, so expand the minimum to it and everything above
+		filterBy["severity"] = IssueSeverities[slices.Index(IssueSeverities, f.MinSeverity):]
+	}
+	if len(f.Types) > 0 {
+		filterBy["type"] = f.Types
+	}
+	if len(f.EntityTypes) > 0 {
+		filterBy["relatedEntity"] = map[string]interface{}{
+			"type": f.EntityTypes,
+		}
+	}
+	if len(f.ProjectIDs) > 0 {
+		filterBy["project"] = f.ProjectIDs
+	}
+	if f.CreatedAfter != nil {
+		filterBy["createdAt"] = map[string]interface{}{
+			"after": f.CreatedAfter.UTC().Format(time.RFC3339),
+		}
+	}
+	if f.UpdatedAfter != nil {
+		filterBy["updatedAt"] = map[string]interface{}{
+			"after": f.UpdatedAfter.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return filterBy, nil
+}
+
+// GetUserByEmail looks up a single Wiz user by email address.
+// The users search filter is a substring match, so results are checked for an exact (case-insensitive) email match.
+func (c *client) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+		query GetUserByEmail($first: Int, $filterBy: UserFilters) {
+			users(first: $first, filterBy: $filterBy) {
+				nodes {
+					id
+					name
+					email
+					isSuspended
+					isAnalyticsEnabled
+					createdAt
+					lastLoginAt
+					identityProviderType
+					identityProvider {
+						id
+						name
+					}
+					effectiveRole {
+						id
+						name
+						scopes
+						isProjectScoped
+					}
+					effectiveAssignedProjects {
+						id
+						name
+						archived
+					}
+					assignedProjects {
+						id
+						name
+						archived
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"first": 100,
+		"filterBy": map[string]interface{}{
+			"search": email,
+		},
+	}
+
+	var result struct {
+		Users UserConnection `json:"users"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	for _, user := range result.Users.Nodes {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+
+	return nil, status.Errorf(codes.NotFound, "wiz user with email %s not found", email)
+}
+
+// UpdateUser applies a partial update to a Wiz user using the updateUser mutation.
+// Requires the write:users permission.
+func (c *client) UpdateUser(ctx context.Context, userID string, patch UpdateUserPatch) error {
+	query := `
+		mutation UpdateUser($input: UpdateUserInput!) {
+			updateUser(input: $input) {
+				user {
+					id
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":    userID,
+			"patch": patch,
+		},
+	}
+
+	var result struct {
+		UpdateUser struct {
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"updateUser"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return nil
+}
+
+// GetProject retrieves a single project from Wiz by ID.
+func (c *client) GetProject(ctx context.Context, projectID string) (*Project, error) {
+	query := `
+		query GetProject($id: ID!) {
+			project(id: $id) {
+				id
+				name
+				description
+				businessUnit
+				archived
+				projectOwners {
+					id
+					email
+				}
+				securityChampions {
+					id
+					email
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"id": projectID,
+	}
+
+	var result struct {
+		Project *Project `json:"project"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if result.Project == nil {
+		return nil, status.Errorf(codes.NotFound, "wiz project %s not found", projectID)
+	}
+
+	return result.Project, nil
+}
+
+// UpdateProject applies a partial update to a Wiz project using the updateProject mutation.
+// Requires the write:projects permission.
+func (c *client) UpdateProject(ctx context.Context, projectID string, patch UpdateProjectPatch) error {
+	query := `
+		mutation UpdateProject($input: UpdateProjectInput!) {
+			updateProject(input: $input) {
+				project {
+					id
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":    projectID,
+			"patch": patch,
+		},
+	}
+
+	var result struct {
+		UpdateProject struct {
+			Project struct {
+				ID string `json:"id"`
+			} `json:"project"`
+		} `json:"updateProject"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return nil
+}
+
+// CreateProject creates a new Wiz project using the createProject mutation.
+// Requires the write:projects permission.
+func (c *client) CreateProject(ctx context.Context, input CreateProjectInput) (*Project, error) {
+	query := `
+		mutation CreateProject($input: CreateProjectInput!) {
+			createProject(input: $input) {
+				project {
+					id
+					name
+					description
+					businessUnit
+					archived
+					projectOwners {
+						id
+						email
+					}
+					securityChampions {
+						id
+						email
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": input,
+	}
+
+	var result struct {
+		CreateProject struct {
+			Project Project `json:"project"`
+		} `json:"createProject"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return &result.CreateProject.Project, nil
+}
+
+// CreateUser creates a new Wiz user using the createUser mutation.
+// Requires the write:users permission.
+func (c *client) CreateUser(ctx context.Context, input CreateUserInput) (*User, error) {
+	query := `
+		mutation CreateUser($input: CreateUserInput!) {
+			createUser(input: $input) {
+				user {
+					id
+					name
+					email
+					effectiveRole {
+						id
+						name
+						scopes
+						isProjectScoped
+					}
+					effectiveAssignedProjects {
+						id
+						name
+						archived
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": input,
+	}
+
+	var result struct {
+		CreateUser struct {
+			User User `json:"user"`
+		} `json:"createUser"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return &result.CreateUser.User, nil
+}
+
+// DeleteUser deletes a Wiz user using the deleteUser mutation.
+// Requires the write:users permission.
+func (c *client) DeleteUser(ctx context.Context, userID string) error {
+	query := `
+		mutation DeleteUser($input: DeleteUserInput!) {
+			deleteUser(input: $input) {
+				_stub
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id": userID,
+		},
+	}
+
+	var result struct {
+		DeleteUser struct {
+			Stub *string `json:"_stub"`
+		} `json:"deleteUser"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return nil
+}
+
+// CreateUserRole creates a custom Wiz role using the createUserRole mutation.
+// Requires the write:users permission.
+func (c *client) CreateUserRole(ctx context.Context, input CreateUserRoleInput) (*UserRole, error) {
+	query := `
+		mutation CreateUserRole($input: CreateUserRoleInput!) {
+			createUserRole(input: $input) {
+				userRole {
+					id
+					name
+					description
+					scopes
+					builtin
+					isProjectScoped
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": input,
+	}
+
+	var result struct {
+		CreateUserRole struct {
+			UserRole UserRole `json:"userRole"`
+		} `json:"createUserRole"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to create user role: %w", err)
+	}
+
+	return &result.CreateUserRole.UserRole, nil
+}
+
+// UpdateUserRole updates a custom Wiz role using the updateUserRole mutation and returns the updated role.
+// Requires the write:users permission.
+func (c *client) UpdateUserRole(ctx context.Context, roleID string, patch UpdateUserRolePatch) (*UserRole, error) {
+	query := `
+		mutation UpdateUserRole($input: UpdateUserRoleInput!) {
+			updateUserRole(input: $input) {
+				userRole {
+					id
+					name
+					description
+					scopes
+					builtin
+					isProjectScoped
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":    roleID,
+			"patch": patch,
+		},
+	}
+
+	var result struct {
+		UpdateUserRole struct {
+			UserRole UserRole `json:"userRole"`
+		} `json:"updateUserRole"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	return &result.UpdateUserRole.UserRole, nil
+}
+
+// DeleteUserRole deletes a custom Wiz role using the deleteUserRole mutation.
+// Requires the write:users permission.
+func (c *client) DeleteUserRole(ctx context.Context, roleID string) error {
+	query := `
+		mutation DeleteUserRole($input: DeleteUserRoleInput!) {
+			deleteUserRole(input: $input) {
+				_stub
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id": roleID,
+		},
+	}
+
+	var result struct {
+		DeleteUserRole struct {
+			Stub *string `json:"_stub"`
+		} `json:"deleteUserRole"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return fmt.Errorf("failed to delete user role: %w", err)
+	}
+
+	return nil
+}
+
+// ListServiceAccounts retrieves a paginated list of service accounts (API clients) from Wiz.
+// Requires the read:service_accounts permission.
+func (c *client) ListServiceAccounts(ctx context.Context, cursor *string) (*ServiceAccountConnection, error) {
+	query := `
+		query ListServiceAccounts($first: Int, $after: String) {
+			serviceAccounts(first: $first, after: $after) {
+				nodes {
+					id
+					name
+					clientId
+					type
+					enabled
+					scopes
+					createdAt
+					lastRotatedAt
+					lastUsedAt
+					expiresAt
+					createdBy {
+						id
+						email
+					}
+					assignedProjects {
+						id
+						name
+					}
+				}
+				pageInfo {
+					endCursor
+					hasNextPage
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"first": 100,
+	}
+	if cursor != nil && *cursor != "" {
+		variables["after"] = *cursor
+	}
+
+	var result struct {
+		ServiceAccounts ServiceAccountConnection `json:"serviceAccounts"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+
+	return &result.ServiceAccounts, nil
+}
+
+// RotateServiceAccountSecret issues a new client secret for a service account using the rotateServiceAccountSecret mutation.
+// The previous secret stops working immediately. Requires the write:service_accounts permission.
+func (c *client) RotateServiceAccountSecret(ctx context.Context, serviceAccountID string) (*ServiceAccountCredentials, error) {
+	query := `
+		mutation RotateServiceAccountSecret($id: ID!) {
+			rotateServiceAccountSecret(ID: $id) {
+				serviceAccount {
+					id
+					clientId
+					clientSecret
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"id": serviceAccountID,
+	}
+
+	var result struct {
+		RotateServiceAccountSecret struct {
+			ServiceAccount ServiceAccountCredentials `json:"serviceAccount"`
+		} `json:"rotateServiceAccountSecret"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to rotate service account secret: %w", err)
+	}
+
+	if result.RotateServiceAccountSecret.ServiceAccount.ClientSecret == "" {
+		return nil, status.Errorf(codes.Internal, "wiz returned no client secret for service account %s", serviceAccountID)
+	}
+
+	return &result.RotateServiceAccountSecret.ServiceAccount, nil
+}
+
+// ListCloudAccounts retrieves a paginated list of the cloud accounts connected to Wiz, with the projects each belongs to.
+// Requires the read:cloud_accounts permission.
+func (c *client) ListCloudAccounts(ctx context.Context, cursor *string) (*CloudAccountConnection, error) {
+	query := `
+		query ListCloudAccounts($first: Int, $after: String) {
+			cloudAccounts(first: $first, after: $after) {
+				nodes {
+					id
+					name
+					externalId
+					cloudProvider
+					status
+					linkedProjects {
+						id
+						name
+						archived
+					}
+				}
+				pageInfo {
+					endCursor
+					hasNextPage
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"first": 100,
+	}
+	if cursor != nil && *cursor != "" {
+		variables["after"] = *cursor
+	}
+
+	var result struct {
+		CloudAccounts CloudAccountConnection `json:"cloudAccounts"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to list cloud accounts: %w", err)
+	}
+
+	return &result.CloudAccounts, nil
+}
+
+// GetCloudAccount retrieves a cloud account and the status of the connectors that scan it.
+// Requires the read:cloud_accounts and read:connectors permissions.
+func (c *client) GetCloudAccount(ctx context.Context, cloudAccountID string) (*CloudAccount, error) {
+	query := `
+		query GetCloudAccount($filterBy: CloudAccountFilters) {
+			cloudAccounts(first: 1, filterBy: $filterBy) {
+				nodes {
+					id
+					name
+					externalId
+					cloudProvider
+					status
+					linkedProjects {
+						id
+						name
+						archived
+					}
+					sourceConnectors {
+						id
+						name
+						status
+						lastActivity
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"filterBy": map[string]interface{}{
+			"id": []string{cloudAccountID},
+		},
+	}
+
+	var result struct {
+		CloudAccounts struct {
+			Nodes []CloudAccount `json:"nodes"`
+		} `json:"cloudAccounts"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to get cloud account: %w", err)
+	}
+
+	if len(result.CloudAccounts.Nodes) == 0 {
+		return nil, status.Errorf(codes.NotFound, "wiz cloud account %s not found", cloudAccountID)
+	}
+
+	return &result.CloudAccounts.Nodes[0], nil
+}
+
+// RequestConnectorScan starts an on-demand scan of the cloud accounts a connector has access to,
+// using the requestConnectorScan mutation. Requires the write:connectors permission.
+func (c *client) RequestConnectorScan(ctx context.Context, connectorID string) error {
+	query := `
+		mutation RequestConnectorScan($input: RequestConnectorScanInput!) {
+			requestConnectorScan(input: $input) {
+				_stub
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id": connectorID,
+		},
+	}
+
+	var result struct {
+		RequestConnectorScan struct {
+			Stub *string `json:"_stub"`
+		} `json:"requestConnectorScan"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return fmt.Errorf("failed to request connector scan: %w", err)
+	}
+
+	return nil
+}
+
+// GetReport retrieves a report and its last run. Requires the read:reports permission.
+func (c *client) GetReport(ctx context.Context, reportID string) (*Report, error) {
+	query := `
+		query GetReport($id: ID!) {
+			report(id: $id) {
+				id
+				name
+				lastRun {
+					status
+					runAt
+					url
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"id": reportID,
+	}
+
+	var result struct {
+		Report *Report `json:"report"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	if result.Report == nil {
+		return nil, status.Errorf(codes.NotFound, "wiz report %s not found", reportID)
+	}
+
+	return result.Report, nil
+}
+
+// RerunReport starts a new run of a report using the rerunReport mutation. The run completes asynchronously;
+// poll GetReport for its status. Requires the write:reports permission.
+func (c *client) RerunReport(ctx context.Context, reportID string) (*Report, error) {
+	query := `
+		mutation RerunReport($input: RerunReportInput!) {
+			rerunReport(input: $input) {
+				report {
+					id
+					name
+					lastRun {
+						status
+						runAt
+						url
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id": reportID,
+		},
+	}
+
+	var result struct {
+		RerunReport struct {
+			Report Report `json:"report"`
+		} `json:"rerunReport"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to rerun report: %w", err)
+	}
+
+	return &result.RerunReport.Report, nil
+}
+
+// ListSAMLIdentityProviders retrieves a paginated list of SAML identity providers with their group mappings.
+// Requires the read:saml_identity_providers permission.
+func (c *client) ListSAMLIdentityProviders(ctx context.Context, cursor *string) (*SAMLIdentityProviderConnection, error) {
+	query := `
+		query ListSAMLIdentityProviders($first: Int, $after: String) {
+			samlIdentityProviders(first: $first, after: $after) {
+				nodes {
+					id
+					name
+					groupMapping {
+						providerGroupId
+						role {
+							id
+							name
+							isProjectScoped
+						}
+						projects {
+							id
+							name
+							archived
+						}
+					}
+				}
+				pageInfo {
+					endCursor
+					hasNextPage
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"first": 100,
+	}
+	if cursor != nil && *cursor != "" {
+		variables["after"] = *cursor
+	}
+
+	var result struct {
+		SAMLIdentityProviders SAMLIdentityProviderConnection `json:"samlIdentityProviders"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to list saml identity providers: %w", err)
+	}
+
+	return &result.SAMLIdentityProviders, nil
+}
+
+// ListAuditLogEntries retrieves a paginated list of the audit log entries recorded after since, oldest first.
+// Requires the read:audit_logs permission.
+func (c *client) ListAuditLogEntries(ctx context.Context, since time.Time, cursor *string) (*AuditLogEntryConnection, error) {
+	query := `
+		query ListAuditLogEntries($first: Int, $after: String, $filterBy: AuditLogEntryFilters, $orderBy: AuditLogEntryOrder) {
+			auditLogEntries(first: $first, after: $after, filterBy: $filterBy, orderBy: $orderBy) {
+				nodes {
+					id
+					action
+					status
+					timestamp
+					actionParameters
+					user {
+						id
+						email
+					}
+					serviceAccount {
+						id
+						name
+					}
+				}
+				pageInfo {
+					endCursor
+					hasNextPage
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"first": 100,
+		"filterBy": map[string]interface{}{
+			"timestamp": map[string]interface{}{"after": since.UTC().Format(time.RFC3339Nano)},
+		},
+		"orderBy": map[string]interface{}{
+			"field":     "TIMESTAMP",
+			"direction": "ASC",
+		},
+	}
+	if cursor != nil && *cursor != "" {
+		variables["after"] = *cursor
+	}
+
+	var result struct {
+		AuditLogEntries AuditLogEntryConnection `json:"auditLogEntries"`
+	}
+	if err := c.graphQLRequest(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to list audit log entries: %w", err)
+	}
+
+	return &result.AuditLogEntries, nil
+}
@@ -0,0 +1,342 @@
+package wiz_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/wiztest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestClient(t *testing.T, server *wiztest.Server) wiz.Client {
+	t.Helper()
+
+	client, err := wiz.NewClient(context.Background(), server.APIURL, server.ClientID, server.ClientSecret, server.TokenURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestListUsersFollowsRelayCursors(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures(), wiztest.WithPageSize(2))
+	client := newTestClient(t, server)
+
+	var emails []string
+	var cursor *string
+	for {
+		users, err := client.ListUsers(ctx, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, user := range users.Nodes {
+			emails = append(emails, user.Email)
+		}
+		if !users.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &users.PageInfo.EndCursor
+	}
+
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, emails)
+	assert.Equal(t, 2, server.Calls("ListUsers"))
+
+	bogus := "not-a-cursor"
+	_, err := client.ListUsers(ctx, &bogus)
+	assert.Error(t, err)
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client := newTestClient(t, server)
+
+	user, err := client.GetUserByEmail(ctx, "BOB@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "user-2", user.ID)
+	assert.Equal(t, []wiz.ProjectRef{{ID: "project-1", Name: "Payments"}}, user.AssignedProjects)
+
+	_, err = client.GetUserByEmail(ctx, "nobody@example.com")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Project references report whether the project is archived.
+	archived := true
+	if err := client.UpdateProject(ctx, "project-1", wiz.UpdateProjectPatch{Archived: &archived}); err != nil {
+		t.Fatal(err)
+	}
+	user, err = client.GetUserByEmail(ctx, "bob@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []wiz.ProjectRef{{ID: "project-1", Name: "Payments", Archived: true}}, user.AssignedProjects)
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fault    wiztest.Fault
+		wantCode codes.Code
+	}{
+		{
+			name:     "graphql internal error",
+			fault:    wiztest.Fault{Errors: []wiztest.Error{{Message: "boom", Code: "INTERNAL"}}},
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "graphql forbidden",
+			fault:    wiztest.Fault{Errors: []wiztest.Error{{Message: "missing read:projects", Code: "FORBIDDEN"}}},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "graphql unauthorized",
+			fault:    wiztest.Fault{Errors: []wiztest.Error{{Message: "not authorized", Code: "UNAUTHORIZED"}}},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "graphql not found",
+			fault:    wiztest.Fault{Errors: []wiztest.Error{{Message: "no such object", Code: "NOT_FOUND"}}},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "graphql error without code",
+			fault:    wiztest.Fault{Errors: []wiztest.Error{{Message: "something went wrong"}}},
+			wantCode: codes.Unknown,
+		},
+		{
+			name: "partial data with permission errors",
+			fault: wiztest.Fault{
+				Errors:      []wiztest.Error{{Message: "not authorized", Code: "FORBIDDEN", Path: []interface{}{"projects", "nodes", 0, "projectOwners"}}},
+				PartialData: true,
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "rate limited",
+			fault:    wiztest.Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second},
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "service unavailable",
+			fault:    wiztest.Fault{StatusCode: http.StatusServiceUnavailable},
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+			client := newTestClient(t, server)
+
+			tt.fault.Times = 1
+			server.InjectFault("ListProjects", tt.fault)
+
+			_, err := client.ListProjects(ctx, nil)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			// The fault is consumed, so the next request succeeds.
+			projects, err := client.ListProjects(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Len(t, projects.Nodes, 2)
+		})
+	}
+}
+
+func TestClientTokenExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("short-lived tokens are refreshed", func(t *testing.T) {
+		server := wiztest.NewServer(t, wiztest.DefaultFixtures(), wiztest.WithTokenTTL(5*time.Second))
+		client := newTestClient(t, server)
+
+		for range 3 {
+			if _, err := client.ListUserRoles(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		assert.Equal(t, 3, server.TokenRequests())
+	})
+
+	t.Run("revoked tokens are rejected", func(t *testing.T) {
+		server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+		client := newTestClient(t, server)
+
+		if _, err := client.ListUserRoles(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+		server.ExpireTokens()
+
+		_, err := client.ListUserRoles(ctx, nil)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestListIssuesFilter(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client := newTestClient(t, server)
+
+	createdAfter := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+	updatedAfter := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filter  wiz.IssueFilter
+		wantIDs []string
+	}{
+		{
+			name:    "no filter",
+			filter:  wiz.IssueFilter{},
+			wantIDs: []string{"issue-1", "issue-2", "issue-3"},
+		},
+		{
+			name:    "minimum severity includes more severe issues",
+			filter:  wiz.IssueFilter{MinSeverity: "HIGH"},
+			wantIDs: []string{"issue-1", "issue-2"},
+		},
+		{
+			name:    "status and entity type",
+			filter:  wiz.IssueFilter{Statuses: []string{"OPEN"}, EntityTypes: []string{"USER_ACCOUNT"}},
+			wantIDs: []string{"issue-1", "issue-3"},
+		},
+		{
+			name:    "issue type and created after",
+			filter:  wiz.IssueFilter{Types: []string{"CLOUD_CONFIGURATION", "THREAT_DETECTION"}, CreatedAfter: &createdAfter},
+			wantIDs: []string{"issue-2", "issue-3"},
+		},
+		{
+			name:    "updated after",
+			filter:  wiz.IssueFilter{UpdatedAfter: &updatedAfter},
+			wantIDs: []string{"issue-1", "issue-3"},
+		},
+		{
+			name:    "project",
+			filter:  wiz.IssueFilter{ProjectIDs: []string{"project-2"}},
+			wantIDs: []string{"issue-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := client.ListIssues(ctx, tt.filter, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, issue := range issues.Nodes {
+				ids = append(ids, issue.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+
+	// Issue details are decoded alongside the filtered fields.
+	issues, err := client.ListIssues(ctx, wiz.IssueFilter{ProjectIDs: []string{"project-1"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, issues.Nodes, 1) {
+		issue := issues.Nodes[0]
+		assert.NotNil(t, issue.DueAt)
+		assert.Nil(t, issue.ResolvedAt)
+		assert.Equal(t, "HBI", issue.Projects[0].RiskProfile.BusinessImpact)
+		assert.Equal(t, "SEC-42", issue.ServiceTickets[0].ExternalID)
+		assert.Equal(t, "Owner notified", issue.Notes[0].Text)
+		if assert.Len(t, issue.Evidence, 2) {
+			assert.Equal(t, "Administrator access", issue.Evidence[0].Name)
+		}
+		assert.NotEmpty(t, issue.SourceRule.ResolutionRecommendation)
+	}
+
+	calls := server.Calls("ListIssues")
+	_, err = client.ListIssues(ctx, wiz.IssueFilter{MinSeverity: "SEVERE"}, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, calls, server.Calls("ListIssues"))
+}
+
+func TestUpdateIssue(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client := newTestClient(t, server)
+
+	rejected := "REJECTED"
+	_, err := client.UpdateIssue(ctx, "issue-1", wiz.UpdateIssuePatch{Status: &rejected})
+	assert.Error(t, err, "closing an issue requires a resolution reason")
+
+	reason := "FALSE_POSITIVE"
+	issue, err := client.UpdateIssue(ctx, "issue-1", wiz.UpdateIssuePatch{Status: &rejected, ResolutionReason: &reason})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "REJECTED", issue.Status)
+	assert.NotNil(t, issue.ResolvedAt)
+
+	note, err := client.CreateIssueNote(ctx, "issue-1", "Test account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Test account", note.Text)
+
+	_, err = client.CreateIssueNote(ctx, "issue-404", "Test account")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGraphQLRateLimit(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client := newTestClient(t, server)
+
+	server.InjectFault("ListUsers", wiztest.Fault{
+		Errors: []wiztest.Error{{Message: "rate limit exceeded", Code: "RATE_LIMIT_EXCEEDED", RetryAfter: 5 * time.Second}},
+		Times:  1,
+	})
+
+	before := time.Now()
+	_, err := client.ListUsers(ctx, nil)
+
+	var gqlErr *wiz.GraphQLError
+	if !assert.ErrorAs(t, err, &gqlErr) {
+		return
+	}
+	assert.Equal(t, 5*time.Second, gqlErr.RetryAfter())
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		rateLimit, ok := st.Details()[0].(*v2.RateLimitDescription)
+		if assert.True(t, ok) {
+			assert.Equal(t, v2.RateLimitDescription_STATUS_OVERLIMIT, rateLimit.GetStatus())
+			assert.WithinDuration(t, before.Add(5*time.Second), rateLimit.GetResetAt().AsTime(), time.Second)
+		}
+	}
+}
+
+func TestGraphQLPartialNotFound(t *testing.T) {
+	ctx := context.Background()
+	server := wiztest.NewServer(t, wiztest.DefaultFixtures())
+	client := newTestClient(t, server)
+
+	// A reference to a deleted object resolves to null with a NOT_FOUND error; the rest of the data is kept.
+	server.InjectFault("ListUsers", wiztest.Fault{
+		Errors:      []wiztest.Error{{Message: "project not found", Code: "NOT_FOUND", Path: []interface{}{"users", "nodes", 1, "effectiveAssignedProjects", 0}}},
+		PartialData: true,
+		Times:       1,
+	})
+
+	users, err := client.ListUsers(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, users.Nodes, 3)
+}
@@ -0,0 +1,123 @@
+package wiz
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Wiz GraphQL error codes reported in extensions.code.
+const (
+	ErrorCodeUnauthenticated   = "UNAUTHENTICATED"
+	ErrorCodeUnauthorized      = "UNAUTHORIZED"
+	ErrorCodeForbidden         = "FORBIDDEN"
+	ErrorCodeNotFound          = "NOT_FOUND"
+	ErrorCodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
+	ErrorCodeInternal          = "INTERNAL"
+	ErrorCodeBadUserInput      = "BAD_USER_INPUT"
+	ErrorCodeValidationFailed  = "GRAPHQL_VALIDATION_FAILED"
+)
+
+// defaultRetryAfter is used for rate limit errors that do not say when to retry, matching the SDK's HTTP 429 default.
+const defaultRetryAfter = 60 * time.Second
+
+// GraphQLErrorExtensions holds the Wiz-specific error details.
+type GraphQLErrorExtensions struct {
+	Code string `json:"code"`
+	// RetryAfter is the number of seconds to wait before retrying a rate limited request.
+	RetryAfter float64 `json:"retryAfter,omitempty"`
+}
+
+// GraphQLError is an entry of the "errors" array of a Wiz GraphQL response.
+// It implements GRPCStatus, so status.Code reports the gRPC code matching extensions.code even when wrapped.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions GraphQLErrorExtensions `json:"extensions"`
+}
+
+func (e *GraphQLError) Error() string {
+	msg := "wiz graphql error"
+	if e.Extensions.Code != "" {
+		msg += " " + e.Extensions.Code
+	}
+	if len(e.Path) > 0 {
+		msg += fmt.Sprintf(" at %v", e.Path)
+	}
+	return msg + ": " + e.Message
+}
+
+// Code returns the gRPC code for the error's extensions.code.
+func (e *GraphQLError) Code() codes.Code {
+	switch e.Extensions.Code {
+	case ErrorCodeUnauthenticated:
+		return codes.Unauthenticated
+	case ErrorCodeUnauthorized, ErrorCodeForbidden:
+		return codes.PermissionDenied
+	case ErrorCodeNotFound:
+		return codes.NotFound
+	case ErrorCodeBadUserInput, ErrorCodeValidationFailed:
+		return codes.InvalidArgument
+	case ErrorCodeRateLimitExceeded, ErrorCodeInternal:
+		// Internal errors are usually transient on the Wiz side, so let the syncer retry them
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// RetryAfter returns how long to wait before retrying a rate limited request, or zero for other errors.
+func (e *GraphQLError) RetryAfter() time.Duration {
+	if e.Extensions.Code != ErrorCodeRateLimitExceeded {
+		return 0
+	}
+	if e.Extensions.RetryAfter > 0 {
+		return time.Duration(e.Extensions.RetryAfter * float64(time.Second))
+	}
+	return defaultRetryAfter
+}
+
+// GRPCStatus converts the error to a gRPC status. Rate limit errors carry a RateLimitDescription
+// so the SDK retries once the limit resets.
+func (e *GraphQLError) GRPCStatus() *status.Status {
+	st := status.New(e.Code(), e.Error())
+
+	if retryAfter := e.RetryAfter(); retryAfter > 0 {
+		withDetails, err := st.WithDetails(v2.RateLimitDescription_builder{
+			Status:    v2.RateLimitDescription_STATUS_OVERLIMIT,
+			Limit:     1,
+			Remaining: 0,
+			ResetAt:   timestamppb.New(time.Now().Add(retryAfter)),
+		}.Build())
+		if err == nil {
+			st = withDetails
+		}
+	}
+
+	return st
+}
+
+// joinGraphQLErrors combines the errors of a response. The first error determines the gRPC code.
+func joinGraphQLErrors(gqlErrs []GraphQLError) error {
+	errs := make([]error, 0, len(gqlErrs))
+	for i := range gqlErrs {
+		errs = append(errs, &gqlErrs[i])
+	}
+	return errors.Join(errs...)
+}
+
+// isPartialNotFound reports whether a response that also carries data only failed to resolve references
+// to objects that no longer exist. Those fields come back null and the rest of the data is usable.
+func isPartialNotFound(gqlErrs []GraphQLError) bool {
+	for _, e := range gqlErrs {
+		if e.Extensions.Code != ErrorCodeNotFound || len(e.Path) == 0 {
+			return false
+		}
+	}
+	return true
+}
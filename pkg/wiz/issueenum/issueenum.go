@@ -0,0 +1,11 @@
+// Package issueenum holds the allowed values of the Wiz issue enums used to filter issues.
+// It has no dependencies, so the configuration schema can offer them without depending on the Wiz API client.
+package issueenum
+
+var (
+	Statuses = []string{"OPEN", "IN_PROGRESS", "RESOLVED", "REJECTED"}
+	// Severities is ordered from least to most severe.
+	Severities  = []string{"INFORMATIONAL", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+	Types       = []string{"TOXIC_COMBINATION", "THREAT_DETECTION", "CLOUD_CONFIGURATION"}
+	EntityTypes = []string{"USER_ACCOUNT", "SERVICE_ACCOUNT", "ACCESS_KEY", "ROLE", "GROUP"}
+)
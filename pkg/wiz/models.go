@@ -1,6 +1,11 @@
 package wiz
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/conductorone/baton-wiz-win/pkg/wiz/issueenum"
+)
 
 // PageInfo represents GraphQL pagination information using Relay cursor pagination.
 type PageInfo struct {
@@ -10,23 +15,39 @@ type PageInfo struct {
 
 // UserRoleRef represents a reference to a user's role.
 type UserRoleRef struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Scopes          []string `json:"scopes"`
+	IsProjectScoped bool     `json:"isProjectScoped"`
 }
 
 // ProjectRef represents a reference to a project assigned to a user.
 type ProjectRef struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+}
+
+// IdentityProviderRef represents the identity provider a user authenticates with.
+type IdentityProviderRef struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
 // User represents a Wiz user (from users query).
 type User struct {
-	ID                        string       `json:"id"`
-	Email                     string       `json:"email"`
-	Name                      string       `json:"name"`
-	EffectiveRole             UserRoleRef  `json:"effectiveRole"`
-	EffectiveAssignedProjects []ProjectRef `json:"effectiveAssignedProjects"`
+	ID                        string               `json:"id"`
+	Email                     string               `json:"email"`
+	Name                      string               `json:"name"`
+	IsSuspended               bool                 `json:"isSuspended"`
+	IsAnalyticsEnabled        bool                 `json:"isAnalyticsEnabled"`
+	CreatedAt                 *time.Time           `json:"createdAt"`            // Can be null
+	LastLoginAt               *time.Time           `json:"lastLoginAt"`          // Null if the user never logged in
+	IdentityProviderType      string               `json:"identityProviderType"` // WIZ for local accounts, SAML for SSO
+	IdentityProvider          *IdentityProviderRef `json:"identityProvider"`     // Null for local accounts
+	EffectiveRole             UserRoleRef          `json:"effectiveRole"`
+	EffectiveAssignedProjects []ProjectRef         `json:"effectiveAssignedProjects"`
+	AssignedProjects          []ProjectRef         `json:"assignedProjects"`
 }
 
 // UserConnection represents a paginated list of users.
@@ -35,6 +56,22 @@ type UserConnection struct {
 	PageInfo PageInfo `json:"pageInfo"`
 }
 
+// UpdateUserPatch holds the fields changed by the updateUser mutation.
+// Nil fields are omitted and left unchanged by Wiz; a non-nil empty AssignedProjectIDs clears all assignments.
+type UpdateUserPatch struct {
+	Role               *string   `json:"role,omitempty"`
+	AssignedProjectIDs *[]string `json:"assignedProjectIds,omitempty"`
+}
+
+// CreateUserInput holds the fields for the createUser mutation.
+type CreateUserInput struct {
+	Name               string   `json:"name"`
+	Email              string   `json:"email"`
+	Role               string   `json:"role"`
+	AssignedProjectIDs []string `json:"assignedProjectIds,omitempty"`
+	SendEmailInvite    bool     `json:"sendEmailInvite"`
+}
+
 // UserRole represents a Wiz role/permission level.
 type UserRole struct {
 	ID              string   `json:"id"`
@@ -45,6 +82,22 @@ type UserRole struct {
 	IsProjectScoped bool     `json:"isProjectScoped"`
 }
 
+// CreateUserRoleInput holds the fields for the createUserRole mutation.
+type CreateUserRoleInput struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description,omitempty"`
+	Scopes          []string `json:"scopes"`
+	IsProjectScoped bool     `json:"isProjectScoped"`
+}
+
+// UpdateUserRolePatch holds the fields changed by the updateUserRole mutation.
+// Nil fields are omitted and left unchanged by Wiz.
+type UpdateUserRolePatch struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Scopes      *[]string `json:"scopes,omitempty"`
+}
+
 // UserRoleConnection represents a paginated list of user roles.
 type UserRoleConnection struct {
 	Nodes    []UserRole `json:"nodes"`
@@ -68,19 +121,226 @@ type Project struct {
 	ID                string             `json:"id"`
 	Name              string             `json:"name"`
 	Description       string             `json:"description"`
+	BusinessUnit      string             `json:"businessUnit"`
+	Archived          bool               `json:"archived"`
 	ProjectOwners     []ProjectOwner     `json:"projectOwners"`
 	SecurityChampions []SecurityChampion `json:"securityChampions"`
 }
 
+// UpdateProjectPatch holds the fields changed by the updateProject mutation.
+// Nil fields are omitted and left unchanged by Wiz.
+type UpdateProjectPatch struct {
+	ProjectOwners     *[]string `json:"projectOwners,omitempty"`
+	SecurityChampions *[]string `json:"securityChampions,omitempty"`
+	Archived          *bool     `json:"archived,omitempty"`
+}
+
+// CreateProjectInput holds the fields for the createProject mutation.
+// ProjectOwners and SecurityChampions hold Wiz user IDs.
+type CreateProjectInput struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description,omitempty"`
+	BusinessUnit      string                    `json:"businessUnit,omitempty"`
+	ProjectOwners     []string                  `json:"projectOwners,omitempty"`
+	SecurityChampions []string                  `json:"securityChampions,omitempty"`
+	CloudAccountLinks []ProjectCloudAccountLink `json:"cloudAccountLinks,omitempty"`
+	RiskProfile       *ProjectRiskProfile       `json:"riskProfile,omitempty"`
+}
+
+// ProjectCloudAccountLink links a cloud account to a project.
+type ProjectCloudAccountLink struct {
+	CloudAccount string `json:"cloudAccount"`
+	Environment  string `json:"environment"`
+	Shared       bool   `json:"shared"`
+}
+
+// ProjectRiskProfile describes how critical a project is to the business.
+type ProjectRiskProfile struct {
+	BusinessImpact string `json:"businessImpact"`
+}
+
+// Allowed values of the Wiz project enums used in CreateProjectInput.
+var (
+	ProjectEnvironments    = []string{"PRODUCTION", "STAGING", "DEVELOPMENT", "TESTING", "OTHER"}
+	ProjectBusinessImpacts = []string{"LBI", "MBI", "HBI"}
+
+	DefaultProjectEnvironment = "PRODUCTION"
+)
+
 // ProjectConnection represents a paginated list of projects.
 type ProjectConnection struct {
 	Nodes    []Project `json:"nodes"`
 	PageInfo PageInfo  `json:"pageInfo"`
 }
 
+// UserRef represents a reference to a Wiz user.
+type UserRef struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// ServiceAccount represents a Wiz service account (API client).
+type ServiceAccount struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ClientID         string       `json:"clientId"`
+	Type             string       `json:"type"`
+	Enabled          *bool        `json:"enabled"` // Can be null
+	Scopes           []string     `json:"scopes"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	LastRotatedAt    *time.Time   `json:"lastRotatedAt"` // Can be null
+	LastUsedAt       *time.Time   `json:"lastUsedAt"`    // Can be null
+	ExpiresAt        *time.Time   `json:"expiresAt"`     // Can be null
+	CreatedBy        *UserRef     `json:"createdBy"`     // Can be null
+	AssignedProjects []ProjectRef `json:"assignedProjects"`
+}
+
+// ServiceAccountConnection represents a paginated list of service accounts.
+type ServiceAccountConnection struct {
+	Nodes    []ServiceAccount `json:"nodes"`
+	PageInfo PageInfo         `json:"pageInfo"`
+}
+
+// ServiceAccountCredentials holds the credentials returned when a service account secret is rotated.
+type ServiceAccountCredentials struct {
+	ID           string `json:"id"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// CloudAccount represents a cloud account connected to Wiz: an AWS account, Azure subscription, GCP project or OCI tenancy.
+type CloudAccount struct {
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	ExternalID     string       `json:"externalId"`    // Provider-side ID, e.g. the AWS account number
+	CloudProvider  string       `json:"cloudProvider"` // AWS, Azure, GCP, OCI, ...
+	Status         string       `json:"status"`
+	LinkedProjects []ProjectRef `json:"linkedProjects"`
+	// SourceConnectors are the connectors scanning the account. Only GetCloudAccount selects them.
+	SourceConnectors []CloudConnector `json:"sourceConnectors,omitempty"`
+}
+
+// CloudConnector represents a Wiz connector, which scans the cloud accounts it has access to.
+type CloudConnector struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Status       string     `json:"status"`       // CONNECTED, INITIAL_SCANNING, PARTIALLY_CONNECTED, ERROR or DISABLED
+	LastActivity *time.Time `json:"lastActivity"` // When the connector last scanned, null before its first scan
+}
+
+// CloudAccountConnection represents a paginated list of cloud accounts.
+type CloudAccountConnection struct {
+	Nodes    []CloudAccount `json:"nodes"`
+	PageInfo PageInfo       `json:"pageInfo"`
+}
+
+// SAMLIdentityProvider represents a SAML identity provider users sign in to Wiz with, e.g. Okta or Entra ID.
+type SAMLIdentityProvider struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	GroupMapping []SAMLGroupMapping `json:"groupMapping"`
+}
+
+// SAMLGroupMapping gives the members of an identity provider group a role, on the listed projects or,
+// when there are none, on the whole Wiz tenant.
+type SAMLGroupMapping struct {
+	ProviderGroupID string       `json:"providerGroupId"`
+	Role            UserRoleRef  `json:"role"`
+	Projects        []ProjectRef `json:"projects"`
+}
+
+// SAMLIdentityProviderConnection represents a paginated list of SAML identity providers.
+type SAMLIdentityProviderConnection struct {
+	Nodes    []SAMLIdentityProvider `json:"nodes"`
+	PageInfo PageInfo               `json:"pageInfo"`
+}
+
+// Report represents a saved Wiz report.
+type Report struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	LastRun *ReportRun `json:"lastRun"` // Null until the report first runs
+}
+
+// ReportRun represents a run of a report.
+type ReportRun struct {
+	Status string     `json:"status"` // PENDING, IN_PROGRESS, COMPLETED, FAILED or EXPIRED
+	RunAt  *time.Time `json:"runAt"`
+	URL    string     `json:"url"` // Download URL of the results, set once the run completed
+}
+
+// ReportRunTerminalStatuses are the statuses of report runs that have finished.
+var ReportRunTerminalStatuses = []string{"COMPLETED", "FAILED", "EXPIRED"}
+
+// ServiceAccountRef represents a reference to a Wiz service account.
+type ServiceAccountRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AuditLogEntry represents an action recorded in the Wiz audit log.
+// ActionParameters holds the variables of the audited mutation, e.g. {"input": {"id": "...", "patch": {...}}}.
+type AuditLogEntry struct {
+	ID               string             `json:"id"`
+	Action           string             `json:"action"` // Mutation name, e.g. UpdateUser, or Login
+	Status           string             `json:"status"` // SUCCESS or FAILED
+	Timestamp        time.Time          `json:"timestamp"`
+	ActionParameters json.RawMessage    `json:"actionParameters"`
+	User             *UserRef           `json:"user"`           // Null when a service account acted
+	ServiceAccount   *ServiceAccountRef `json:"serviceAccount"` // Null when a user acted
+}
+
+// AuditLogEntryConnection represents a paginated list of audit log entries.
+type AuditLogEntryConnection struct {
+	Nodes    []AuditLogEntry `json:"nodes"`
+	PageInfo PageInfo        `json:"pageInfo"`
+}
+
 // SourceRule represents the rule that triggered an issue.
+// The control fields are only set when the rule is a Wiz control, e.g. for toxic combinations.
 type SourceRule struct {
-	Name string `json:"name"`
+	ID                       string                `json:"id"`
+	Name                     string                `json:"name"`
+	ControlDescription       string                `json:"controlDescription"`
+	ResolutionRecommendation string                `json:"resolutionRecommendation"`
+	SecuritySubCategories    []SecuritySubCategory `json:"securitySubCategories"`
+}
+
+// SecuritySubCategory represents a compliance framework category a control maps to, e.g. "CIS 1.10".
+type SecuritySubCategory struct {
+	Title    string `json:"title"`
+	Category struct {
+		Name string `json:"name"`
+	} `json:"category"`
+}
+
+// IssueNote represents a comment left on an issue by a user or service account.
+type IssueNote struct {
+	CreatedAt      time.Time          `json:"createdAt"`
+	Text           string             `json:"text"`
+	User           *UserRef           `json:"user"`           // Null when a service account wrote the note
+	ServiceAccount *ServiceAccountRef `json:"serviceAccount"` // Null when a user wrote the note
+}
+
+// ServiceTicket represents a ticket opened for an issue in an external system such as Jira or ServiceNow.
+type ServiceTicket struct {
+	ExternalID string `json:"externalId"`
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+}
+
+// IssueEvidence represents a finding backing an issue, e.g. one of the risks chained by a toxic combination.
+type IssueEvidence struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// IssueProject represents a project an issue belongs to.
+type IssueProject struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	BusinessUnit string             `json:"businessUnit"`
+	RiskProfile  ProjectRiskProfile `json:"riskProfile"`
 }
 
 // EntitySnapshot represents a cloud resource affected by an issue.
@@ -94,13 +354,55 @@ type EntitySnapshot struct {
 
 // Issue represents a Wiz security issue/finding.
 type Issue struct {
-	ID             string         `json:"id"`
-	Type           string         `json:"type"`
-	Severity       string         `json:"severity"`
-	Status         string         `json:"status"`
-	CreatedAt      time.Time      `json:"createdAt"`
-	SourceRule     SourceRule     `json:"sourceRule"`
-	EntitySnapshot EntitySnapshot `json:"entitySnapshot"`
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Severity       string          `json:"severity"`
+	Status         string          `json:"status"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+	DueAt          *time.Time      `json:"dueAt"`      // Null when no due date is set
+	ResolvedAt     *time.Time      `json:"resolvedAt"` // Null while the issue is open
+	SourceRule     SourceRule      `json:"sourceRule"`
+	EntitySnapshot EntitySnapshot  `json:"entitySnapshot"`
+	Projects       []IssueProject  `json:"projects"`
+	ServiceTickets []ServiceTicket `json:"serviceTickets"`
+	Notes          []IssueNote     `json:"notes"`
+	Evidence       []IssueEvidence `json:"evidence"`
+}
+
+// Allowed values of the Wiz issue enums used in IssueFilter, see the issueenum package.
+var (
+	IssueStatuses = issueenum.Statuses
+	// IssueSeverities is ordered from least to most severe.
+	IssueSeverities  = issueenum.Severities
+	IssueTypes       = issueenum.Types
+	IssueEntityTypes = issueenum.EntityTypes
+
+	// IssueRejectionReasons are the resolution reasons accepted when an issue is rejected rather than resolved.
+	IssueRejectionReasons = []string{"FALSE_POSITIVE", "EXCEPTION", "WONT_FIX"}
+
+	DefaultIssueStatuses    = []string{"OPEN", "IN_PROGRESS"}
+	DefaultIssueEntityTypes = []string{"USER_ACCOUNT", "SERVICE_ACCOUNT"}
+)
+
+// IssueFilter selects the issues returned by ListIssues. Empty fields do not filter.
+type IssueFilter struct {
+	Statuses     []string
+	MinSeverity  string // Issues of this severity or higher
+	EntityTypes  []string
+	Types        []string
+	ProjectIDs   []string
+	CreatedAfter *time.Time
+	UpdatedAfter *time.Time
+}
+
+// UpdateIssuePatch holds the fields changed by the updateIssue mutation.
+// Nil fields are omitted and left unchanged by Wiz.
+type UpdateIssuePatch struct {
+	Status           *string    `json:"status,omitempty"`
+	ResolutionReason *string    `json:"resolutionReason,omitempty"` // Required when resolving or rejecting
+	Note             *string    `json:"note,omitempty"`             // Recorded with the status change
+	DueAt            *time.Time `json:"dueAt,omitempty"`
 }
 
 // IssueConnection represents a paginated list of issues.
@@ -111,13 +413,8 @@ type IssueConnection struct {
 
 // GraphQL response wrapper types.
 type graphQLResponse struct {
-	Data   interface{}    `json:"data"`
-	Errors []graphQLError `json:"errors,omitempty"`
-}
-
-type graphQLError struct {
-	Message string        `json:"message"`
-	Path    []interface{} `json:"path,omitempty"`
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
 }
 
 // Specific response types for each query.
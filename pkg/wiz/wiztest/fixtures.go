@@ -0,0 +1,78 @@
+package wiztest
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+// Fixtures holds the Wiz objects served by a fake Server.
+type Fixtures struct {
+	Users           []wiz.User
+	Projects        []wiz.Project
+	Roles           []wiz.UserRole
+	Issues          []wiz.Issue
+	ServiceAccounts []wiz.ServiceAccount
+	CloudAccounts   []wiz.CloudAccount
+	AuditLogEntries []wiz.AuditLogEntry
+	Reports         []wiz.Report
+
+	SAMLIdentityProviders []wiz.SAMLIdentityProvider
+}
+
+// DefaultFixtures returns the fixtures bundled with this package: three users, two projects,
+// three built-in roles, three issues, two service accounts, three cloud accounts, nine audit log entries, one report
+// and one SAML identity provider with two group mappings.
+func DefaultFixtures() *Fixtures {
+	fixtures, err := loadFixtures(defaultFixtures, "fixtures")
+	if err != nil {
+		panic(fmt.Sprintf("wiztest: invalid bundled fixtures: %v", err))
+	}
+	return fixtures
+}
+
+// LoadFixtures reads users.json, projects.json, roles.json, issues.json, service_accounts.json, cloud_accounts.json,
+// audit_log.json, reports.json and saml_identity_providers.json from dir.
+// Each file holds a JSON array of the matching wiz model; missing files are treated as empty.
+func LoadFixtures(dir string) (*Fixtures, error) {
+	return loadFixtures(os.DirFS(dir), ".")
+}
+
+func loadFixtures(fsys fs.FS, dir string) (*Fixtures, error) {
+	fixtures := &Fixtures{}
+	files := map[string]interface{}{
+		"users.json":            &fixtures.Users,
+		"projects.json":         &fixtures.Projects,
+		"roles.json":            &fixtures.Roles,
+		"issues.json":           &fixtures.Issues,
+		"service_accounts.json": &fixtures.ServiceAccounts,
+		"cloud_accounts.json":   &fixtures.CloudAccounts,
+		"audit_log.json":        &fixtures.AuditLogEntries,
+		"reports.json":          &fixtures.Reports,
+
+		"saml_identity_providers.json": &fixtures.SAMLIdentityProviders,
+	}
+
+	for name, target := range files {
+		data, err := fs.ReadFile(fsys, dir+"/"+name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+
+	return fixtures, nil
+}
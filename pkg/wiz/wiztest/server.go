@@ -0,0 +1,1071 @@
+// Package wiztest provides a fake Wiz API for hermetic tests.
+// It serves an OAuth2 client credentials token endpoint and a GraphQL endpoint that answers the
+// operations issued by wiz.Client from in-memory fixtures, with Relay cursor pagination and
+// injectable failures (GraphQL errors, HTTP 429 and 5xx responses, and expired tokens).
+package wiztest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-wiz-win/pkg/wiz"
+)
+
+const (
+	// DefaultClientID and DefaultClientSecret are the credentials accepted by the token endpoint unless overridden.
+	DefaultClientID     = "wiztest-client-id"
+	DefaultClientSecret = "wiztest-client-secret"
+
+	defaultPageSize = 100
+	defaultTokenTTL = time.Hour
+	cursorPrefix    = "arrayconnection:"
+)
+
+var operationNameRe = regexp.MustCompile(`^\s*(?:query|mutation)\s+(\w+)`)
+
+// Error is a GraphQL error returned in the response "errors" array.
+type Error struct {
+	Message string
+	// Code is reported as extensions.code, e.g. "UNAUTHORIZED", "NOT_FOUND" or "RATE_LIMIT_EXCEEDED".
+	Code string
+	Path []interface{}
+	// RetryAfter is reported as extensions.retryAfter in seconds.
+	RetryAfter time.Duration
+}
+
+// Fault describes a failure returned instead of (or alongside) the normal response to an operation.
+type Fault struct {
+	// StatusCode, when set, is returned with an empty JSON body instead of the GraphQL response (e.g. 429 or 503).
+	StatusCode int
+	// RetryAfter sets the Retry-After header on StatusCode responses.
+	RetryAfter time.Duration
+	// Errors are returned in the GraphQL "errors" array with a 200 status.
+	Errors []Error
+	// PartialData also returns the normal "data" alongside Errors, as Wiz does when only some fields fail.
+	PartialData bool
+	// Times is the number of requests the fault applies to. Zero means every request.
+	Times int
+}
+
+type fault struct {
+	operation string
+	Fault
+	remaining int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithPageSize caps the number of nodes returned per page, regardless of the requested "first".
+func WithPageSize(size int) Option {
+	return func(s *Server) {
+		s.pageSize = size
+	}
+}
+
+// WithTokenTTL sets the expires_in of issued access tokens. Tokens are rejected with 401 once expired.
+// The oauth2 package refreshes tokens 10 seconds before they expire, so a TTL under 10 seconds
+// makes the client fetch a new token before every request.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.tokenTTL = ttl
+	}
+}
+
+// WithClientCredentials sets the client ID and secret accepted by the token endpoint.
+func WithClientCredentials(clientID, clientSecret string) Option {
+	return func(s *Server) {
+		s.ClientID = clientID
+		s.ClientSecret = clientSecret
+	}
+}
+
+// Server is a fake Wiz API backed by fixtures. Mutations update the in-memory fixtures,
+// so later queries observe them.
+type Server struct {
+	// APIURL is the GraphQL endpoint and TokenURL the OAuth2 token endpoint, as passed to wiz.NewClient.
+	APIURL       string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+
+	server   *httptest.Server
+	pageSize int
+	tokenTTL time.Duration
+
+	mu            sync.Mutex
+	fixtures      Fixtures
+	tokens        map[string]time.Time
+	faults        []*fault
+	calls         map[string]int
+	tokenRequests int
+	nextID        int
+}
+
+// NewServer starts a fake Wiz API serving a copy of fixtures. The server is closed when the test ends.
+func NewServer(tb testing.TB, fixtures *Fixtures, opts ...Option) *Server {
+	tb.Helper()
+
+	s := &Server{
+		ClientID:     DefaultClientID,
+		ClientSecret: DefaultClientSecret,
+		pageSize:     defaultPageSize,
+		tokenTTL:     defaultTokenTTL,
+		tokens:       make(map[string]time.Time),
+		calls:        make(map[string]int),
+	}
+	if fixtures != nil {
+		s.fixtures = Fixtures{
+			Users:           slices.Clone(fixtures.Users),
+			Projects:        slices.Clone(fixtures.Projects),
+			Roles:           slices.Clone(fixtures.Roles),
+			Issues:          slices.Clone(fixtures.Issues),
+			ServiceAccounts: slices.Clone(fixtures.ServiceAccounts),
+			CloudAccounts:   slices.Clone(fixtures.CloudAccounts),
+			AuditLogEntries: slices.Clone(fixtures.AuditLogEntries),
+			Reports:         slices.Clone(fixtures.Reports),
+
+			SAMLIdentityProviders: slices.Clone(fixtures.SAMLIdentityProviders),
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", s.handleToken)
+	mux.HandleFunc("/graphql", s.handleGraphQL)
+	s.server = httptest.NewServer(mux)
+	tb.Cleanup(s.server.Close)
+
+	s.APIURL = s.server.URL + "/graphql"
+	s.TokenURL = s.server.URL + "/oauth/token"
+
+	return s
+}
+
+// InjectFault makes requests for operation (e.g. "ListUsers") fail as described by f.
+// An empty operation matches every GraphQL request. Faults are consumed in the order they were injected.
+func (s *Server) InjectFault(operation string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{operation: operation, Fault: f, remaining: f.Times})
+}
+
+// ExpireTokens invalidates every access token issued so far, as if they had expired server-side.
+// Clients that keep using a cached token receive 401 responses.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token := range s.tokens {
+		s.tokens[token] = time.Time{}
+	}
+}
+
+// Calls returns the number of GraphQL requests received for operation, including failed ones.
+func (s *Server) Calls(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[operation]
+}
+
+// TokenRequests returns the number of successful token requests.
+func (s *Server) TokenRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenRequests
+}
+
+// Fixtures returns a snapshot of the current fixtures, including changes made by mutations.
+func (s *Server) Fixtures() Fixtures {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Fixtures{
+		Users:           slices.Clone(s.fixtures.Users),
+		Projects:        slices.Clone(s.fixtures.Projects),
+		Roles:           slices.Clone(s.fixtures.Roles),
+		Issues:          slices.Clone(s.fixtures.Issues),
+		ServiceAccounts: slices.Clone(s.fixtures.ServiceAccounts),
+		CloudAccounts:   slices.Clone(s.fixtures.CloudAccounts),
+		AuditLogEntries: slices.Clone(s.fixtures.AuditLogEntries),
+		Reports:         slices.Clone(s.fixtures.Reports),
+
+		SAMLIdentityProviders: slices.Clone(s.fixtures.SAMLIdentityProviders),
+	}
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if r.PostForm.Get("audience") != "wiz-api" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "audience must be wiz-api"})
+		return
+	}
+	if r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	token := randomHex(16)
+
+	s.mu.Lock()
+	s.tokens[token] = time.Now().Add(s.tokenTTL)
+	s.tokenRequests++
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.tokenTTL.Seconds()),
+	})
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, graphQLErrors(Error{Message: "invalid request body", Code: "BAD_REQUEST"}))
+		return
+	}
+
+	operation := ""
+	if m := operationNameRe.FindStringSubmatch(body.Query); m != nil {
+		operation = m[1]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[operation]++
+
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	f := s.takeFault(operation)
+	if f != nil && f.StatusCode != 0 {
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+		}
+		writeJSON(w, f.StatusCode, map[string]interface{}{})
+		return
+	}
+
+	data, gqlErr := s.execute(operation, body.Variables)
+
+	resp := map[string]interface{}{"data": data}
+	switch {
+	case f != nil && len(f.Errors) > 0:
+		resp = graphQLErrors(f.Errors...)
+		if f.PartialData && gqlErr == nil {
+			resp["data"] = data
+		}
+	case gqlErr != nil:
+		resp = graphQLErrors(*gqlErr)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// authorized reports whether the request carries an unexpired access token. Callers must hold s.mu.
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	expiresAt, ok := s.tokens[token]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// takeFault returns the first fault matching operation and consumes one of its uses. Callers must hold s.mu.
+func (s *Server) takeFault(operation string) *Fault {
+	for i, f := range s.faults {
+		if f.operation != "" && f.operation != operation {
+			continue
+		}
+		if f.Times > 0 {
+			f.remaining--
+			if f.remaining <= 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return &f.Fault
+	}
+	return nil
+}
+
+// execute runs a single GraphQL operation against the fixtures. Callers must hold s.mu.
+func (s *Server) execute(operation string, variables map[string]interface{}) (interface{}, *Error) {
+	switch operation {
+	case "ListUsers":
+		conn, err := paginate(s.fixtures.Users, variables, "after", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"users": conn}, nil
+
+	case "GetUserByEmail":
+		var filterBy struct {
+			Search string `json:"search"`
+		}
+		if err := decodeVariable(variables, "filterBy", &filterBy); err != nil {
+			return nil, err
+		}
+		search := strings.ToLower(filterBy.Search)
+		matches := []wiz.User{}
+		for _, user := range s.fixtures.Users {
+			if strings.Contains(strings.ToLower(user.Email), search) || strings.Contains(strings.ToLower(user.Name), search) {
+				matches = append(matches, user)
+			}
+		}
+		conn, err := paginate(matches, variables, "after", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"users": conn}, nil
+
+	case "ListProjects":
+		conn, err := paginate(s.fixtures.Projects, variables, "cursor", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"projects": conn}, nil
+
+	case "GetProject":
+		id, _ := variables["id"].(string)
+		if i := s.projectIndex(id); i >= 0 {
+			return map[string]interface{}{"project": s.fixtures.Projects[i]}, nil
+		}
+		return map[string]interface{}{"project": nil}, nil
+
+	case "ListUserRoles":
+		return map[string]interface{}{"userRolesV2": nonNil(s.fixtures.Roles)}, nil
+
+	case "ListIssues":
+		issues, err := filterIssues(s.fixtures.Issues, variables)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := paginate(issues, variables, "cursor", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"issues": conn}, nil
+
+	case "ListServiceAccounts":
+		conn, err := paginate(s.fixtures.ServiceAccounts, variables, "after", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"serviceAccounts": conn}, nil
+
+	case "ListCloudAccounts":
+		conn, err := paginate(s.fixtures.CloudAccounts, variables, "after", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"cloudAccounts": conn}, nil
+
+	case "ListSAMLIdentityProviders":
+		conn, err := paginate(s.fixtures.SAMLIdentityProviders, variables, "after", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"samlIdentityProviders": conn}, nil
+
+	case "GetCloudAccount":
+		var filterBy struct {
+			ID []string `json:"id"`
+		}
+		if err := decodeVariable(variables, "filterBy", &filterBy); err != nil {
+			return nil, err
+		}
+		nodes := []wiz.CloudAccount{}
+		for _, account := range s.fixtures.CloudAccounts {
+			if slices.Contains(filterBy.ID, account.ID) {
+				nodes = append(nodes, account)
+			}
+		}
+		return map[string]interface{}{"cloudAccounts": map[string]interface{}{"nodes": nodes}}, nil
+
+	case "GetReport":
+		id, _ := variables["id"].(string)
+		i := s.reportIndex(id)
+		if i < 0 {
+			return map[string]interface{}{"report": nil}, nil
+		}
+		report := &s.fixtures.Reports[i]
+		if report.LastRun != nil && !slices.Contains(wiz.ReportRunTerminalStatuses, report.LastRun.Status) {
+			// Runs complete the first time their status is polled
+			report.LastRun.Status = "COMPLETED"
+			report.LastRun.URL = fmt.Sprintf("https://reports.wiz.io/%s/%d.csv", report.ID, report.LastRun.RunAt.Unix())
+		}
+		return map[string]interface{}{"report": *report}, nil
+
+	case "ListAuditLogEntries":
+		entries, err := filterAuditLogEntries(s.fixtures.AuditLogEntries, variables)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := paginate(entries, variables, "after", s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"auditLogEntries": conn}, nil
+
+	case "UpdateUser":
+		return s.updateUser(variables)
+
+	case "UpdateProject":
+		return s.updateProject(variables)
+
+	case "CreateUser":
+		return s.createUser(variables)
+
+	case "CreateProject":
+		return s.createProject(variables)
+
+	case "DeleteUser":
+		var input struct {
+			ID string `json:"id"`
+		}
+		if err := decodeVariable(variables, "input", &input); err != nil {
+			return nil, err
+		}
+		i := s.userIndex(input.ID)
+		if i < 0 {
+			return nil, notFound("user", input.ID)
+		}
+		s.fixtures.Users = slices.Delete(s.fixtures.Users, i, i+1)
+		return map[string]interface{}{"deleteUser": map[string]interface{}{"_stub": nil}}, nil
+
+	case "CreateUserRole":
+		var input wiz.CreateUserRoleInput
+		if err := decodeVariable(variables, "input", &input); err != nil {
+			return nil, err
+		}
+		s.nextID++
+		role := wiz.UserRole{
+			ID:              fmt.Sprintf("wiztest-role-%d", s.nextID),
+			Name:            input.Name,
+			Description:     input.Description,
+			Scopes:          input.Scopes,
+			IsProjectScoped: input.IsProjectScoped,
+		}
+		s.fixtures.Roles = append(s.fixtures.Roles, role)
+		return map[string]interface{}{"createUserRole": map[string]interface{}{"userRole": role}}, nil
+
+	case "UpdateUserRole":
+		return s.updateUserRole(variables)
+
+	case "UpdateIssue":
+		return s.updateIssue(variables)
+
+	case "CreateIssueNote":
+		var input struct {
+			IssueID string `json:"issueId"`
+			Text    string `json:"text"`
+		}
+		if err := decodeVariable(variables, "input", &input); err != nil {
+			return nil, err
+		}
+		i := s.issueIndex(input.IssueID)
+		if i < 0 {
+			return nil, notFound("issue", input.IssueID)
+		}
+		// Notes are attributed to the service account the server authenticates
+		note := wiz.IssueNote{
+			CreatedAt:      time.Now().UTC(),
+			Text:           input.Text,
+			ServiceAccount: &wiz.ServiceAccountRef{ID: s.ClientID, Name: s.ClientID},
+		}
+		s.fixtures.Issues[i].Notes = append(s.fixtures.Issues[i].Notes, note)
+		return map[string]interface{}{"createIssueNote": map[string]interface{}{"issueNote": note}}, nil
+
+	case "DeleteUserRole":
+		var input struct {
+			ID string `json:"id"`
+		}
+		if err := decodeVariable(variables, "input", &input); err != nil {
+			return nil, err
+		}
+		i, gqlErr := s.customRoleIndex(input.ID)
+		if gqlErr != nil {
+			return nil, gqlErr
+		}
+		s.fixtures.Roles = slices.Delete(s.fixtures.Roles, i, i+1)
+		return map[string]interface{}{"deleteUserRole": map[string]interface{}{"_stub": nil}}, nil
+
+	case "RequestConnectorScan":
+		var input struct {
+			ID string `json:"id"`
+		}
+		if err := decodeVariable(variables, "input", &input); err != nil {
+			return nil, err
+		}
+		for i := range s.fixtures.CloudAccounts {
+			for j := range s.fixtures.CloudAccounts[i].SourceConnectors {
+				connector := &s.fixtures.CloudAccounts[i].SourceConnectors[j]
+				if connector.ID != input.ID {
+					continue
+				}
+				if connector.Status == "DISABLED" {
+					return nil, &Error{Message: fmt.Sprintf("connector %s is disabled", input.ID), Code: "BAD_USER_INPUT"}
+				}
+				now := time.Now().UTC()
+				connector.LastActivity = &now
+				return map[string]interface{}{"requestConnectorScan": map[string]interface{}{"_stub": nil}}, nil
+			}
+		}
+		return nil, notFound("connector", input.ID)
+
+	case "RerunReport":
+		var input struct {
+			ID string `json:"id"`
+		}
+		if err := decodeVariable(variables, "input", &input); err != nil {
+			return nil, err
+		}
+		i := s.reportIndex(input.ID)
+		if i < 0 {
+			return nil, notFound("report", input.ID)
+		}
+		now := time.Now().UTC()
+		s.fixtures.Reports[i].LastRun = &wiz.ReportRun{Status: "IN_PROGRESS", RunAt: &now}
+		return map[string]interface{}{"rerunReport": map[string]interface{}{"report": s.fixtures.Reports[i]}}, nil
+
+	case "RotateServiceAccountSecret":
+		id, _ := variables["id"].(string)
+		for i := range s.fixtures.ServiceAccounts {
+			sa := &s.fixtures.ServiceAccounts[i]
+			if sa.ID != id {
+				continue
+			}
+			now := time.Now().UTC()
+			sa.LastRotatedAt = &now
+			return map[string]interface{}{
+				"rotateServiceAccountSecret": map[string]interface{}{
+					"serviceAccount": wiz.ServiceAccountCredentials{ID: sa.ID, ClientID: sa.ClientID, ClientSecret: randomHex(24)},
+				},
+			}, nil
+		}
+		return nil, notFound("service account", id)
+
+	default:
+		return nil, &Error{Message: fmt.Sprintf("wiztest: unsupported operation %q", operation), Code: "GRAPHQL_VALIDATION_FAILED"}
+	}
+}
+
+func (s *Server) updateUser(variables map[string]interface{}) (interface{}, *Error) {
+	var input struct {
+		ID    string              `json:"id"`
+		Patch wiz.UpdateUserPatch `json:"patch"`
+	}
+	if err := decodeVariable(variables, "input", &input); err != nil {
+		return nil, err
+	}
+	i := s.userIndex(input.ID)
+	if i < 0 {
+		return nil, notFound("user", input.ID)
+	}
+	user := &s.fixtures.Users[i]
+
+	if input.Patch.Role != nil {
+		role, err := s.roleRef(*input.Patch.Role)
+		if err != nil {
+			return nil, err
+		}
+		user.EffectiveRole = role
+	}
+	if input.Patch.AssignedProjectIDs != nil {
+		projects, err := s.projectRefs(*input.Patch.AssignedProjectIDs)
+		if err != nil {
+			return nil, err
+		}
+		user.AssignedProjects = projects
+		user.EffectiveAssignedProjects = projects
+	}
+
+	return map[string]interface{}{"updateUser": map[string]interface{}{"user": map[string]string{"id": user.ID}}}, nil
+}
+
+func (s *Server) updateProject(variables map[string]interface{}) (interface{}, *Error) {
+	var input struct {
+		ID    string                 `json:"id"`
+		Patch wiz.UpdateProjectPatch `json:"patch"`
+	}
+	if err := decodeVariable(variables, "input", &input); err != nil {
+		return nil, err
+	}
+	i := s.projectIndex(input.ID)
+	if i < 0 {
+		return nil, notFound("project", input.ID)
+	}
+	project := &s.fixtures.Projects[i]
+
+	if input.Patch.ProjectOwners != nil {
+		owners := []wiz.ProjectOwner{}
+		for _, userID := range *input.Patch.ProjectOwners {
+			j := s.userIndex(userID)
+			if j < 0 {
+				return nil, notFound("user", userID)
+			}
+			owners = append(owners, wiz.ProjectOwner{ID: userID, Email: s.fixtures.Users[j].Email})
+		}
+		project.ProjectOwners = owners
+	}
+	if input.Patch.SecurityChampions != nil {
+		champions := []wiz.SecurityChampion{}
+		for _, userID := range *input.Patch.SecurityChampions {
+			j := s.userIndex(userID)
+			if j < 0 {
+				return nil, notFound("user", userID)
+			}
+			champions = append(champions, wiz.SecurityChampion{ID: userID, Email: s.fixtures.Users[j].Email})
+		}
+		project.SecurityChampions = champions
+	}
+	if input.Patch.Archived != nil {
+		project.Archived = *input.Patch.Archived
+		s.archiveProjectRefs(project.ID, project.Archived)
+	}
+
+	return map[string]interface{}{"updateProject": map[string]interface{}{"project": map[string]string{"id": project.ID}}}, nil
+}
+
+func (s *Server) createProject(variables map[string]interface{}) (interface{}, *Error) {
+	var input wiz.CreateProjectInput
+	if err := decodeVariable(variables, "input", &input); err != nil {
+		return nil, err
+	}
+
+	s.nextID++
+	project := wiz.Project{
+		ID:                fmt.Sprintf("wiztest-project-%d", s.nextID),
+		Name:              input.Name,
+		Description:       input.Description,
+		BusinessUnit:      input.BusinessUnit,
+		ProjectOwners:     []wiz.ProjectOwner{},
+		SecurityChampions: []wiz.SecurityChampion{},
+	}
+	for _, userID := range input.ProjectOwners {
+		j := s.userIndex(userID)
+		if j < 0 {
+			return nil, notFound("user", userID)
+		}
+		project.ProjectOwners = append(project.ProjectOwners, wiz.ProjectOwner{ID: userID, Email: s.fixtures.Users[j].Email})
+	}
+	for _, userID := range input.SecurityChampions {
+		j := s.userIndex(userID)
+		if j < 0 {
+			return nil, notFound("user", userID)
+		}
+		project.SecurityChampions = append(project.SecurityChampions, wiz.SecurityChampion{ID: userID, Email: s.fixtures.Users[j].Email})
+	}
+	for _, link := range input.CloudAccountLinks {
+		j := slices.IndexFunc(s.fixtures.CloudAccounts, func(a wiz.CloudAccount) bool { return a.ID == link.CloudAccount })
+		if j < 0 {
+			return nil, notFound("cloud account", link.CloudAccount)
+		}
+		account := &s.fixtures.CloudAccounts[j]
+		account.LinkedProjects = append(account.LinkedProjects, wiz.ProjectRef{ID: project.ID, Name: project.Name})
+	}
+	s.fixtures.Projects = append(s.fixtures.Projects, project)
+
+	return map[string]interface{}{"createProject": map[string]interface{}{"project": project}}, nil
+}
+
+func (s *Server) createUser(variables map[string]interface{}) (interface{}, *Error) {
+	var input wiz.CreateUserInput
+	if err := decodeVariable(variables, "input", &input); err != nil {
+		return nil, err
+	}
+	for _, user := range s.fixtures.Users {
+		if strings.EqualFold(user.Email, input.Email) {
+			return nil, &Error{Message: fmt.Sprintf("user with email %s already exists", input.Email), Code: "BAD_USER_INPUT"}
+		}
+	}
+	role, gqlErr := s.roleRef(input.Role)
+	if gqlErr != nil {
+		return nil, gqlErr
+	}
+	projects, gqlErr := s.projectRefs(input.AssignedProjectIDs)
+	if gqlErr != nil {
+		return nil, gqlErr
+	}
+
+	s.nextID++
+	now := time.Now().UTC()
+	user := wiz.User{
+		ID:                        fmt.Sprintf("wiztest-user-%d", s.nextID),
+		Name:                      input.Name,
+		Email:                     input.Email,
+		CreatedAt:                 &now,
+		IdentityProviderType:      "WIZ",
+		EffectiveRole:             role,
+		EffectiveAssignedProjects: projects,
+		AssignedProjects:          projects,
+	}
+	s.fixtures.Users = append(s.fixtures.Users, user)
+
+	return map[string]interface{}{"createUser": map[string]interface{}{"user": user}}, nil
+}
+
+func (s *Server) updateUserRole(variables map[string]interface{}) (interface{}, *Error) {
+	var input struct {
+		ID    string                  `json:"id"`
+		Patch wiz.UpdateUserRolePatch `json:"patch"`
+	}
+	if err := decodeVariable(variables, "input", &input); err != nil {
+		return nil, err
+	}
+	i, gqlErr := s.customRoleIndex(input.ID)
+	if gqlErr != nil {
+		return nil, gqlErr
+	}
+
+	role := &s.fixtures.Roles[i]
+	if input.Patch.Name != nil {
+		role.Name = *input.Patch.Name
+	}
+	if input.Patch.Description != nil {
+		role.Description = *input.Patch.Description
+	}
+	if input.Patch.Scopes != nil {
+		role.Scopes = *input.Patch.Scopes
+	}
+
+	return map[string]interface{}{"updateUserRole": map[string]interface{}{"userRole": *role}}, nil
+}
+
+// updateIssue applies an updateIssue patch. As in Wiz, closing an issue requires a resolution reason.
+func (s *Server) updateIssue(variables map[string]interface{}) (interface{}, *Error) {
+	var input struct {
+		ID    string               `json:"id"`
+		Patch wiz.UpdateIssuePatch `json:"patch"`
+	}
+	if err := decodeVariable(variables, "input", &input); err != nil {
+		return nil, err
+	}
+	i := s.issueIndex(input.ID)
+	if i < 0 {
+		return nil, notFound("issue", input.ID)
+	}
+
+	issue := &s.fixtures.Issues[i]
+	now := time.Now().UTC()
+	if input.Patch.Status != nil {
+		status := *input.Patch.Status
+		if !slices.Contains(wiz.IssueStatuses, status) {
+			return nil, &Error{Message: fmt.Sprintf("invalid issue status %s", status), Code: "BAD_USER_INPUT"}
+		}
+		closed := status == "RESOLVED" || status == "REJECTED"
+		if closed && input.Patch.ResolutionReason == nil {
+			return nil, &Error{Message: "a resolution reason is required to close an issue", Code: "BAD_USER_INPUT"}
+		}
+		issue.Status = status
+		issue.ResolvedAt = nil
+		if closed {
+			issue.ResolvedAt = &now
+		}
+	}
+	if input.Patch.Note != nil {
+		issue.Notes = append(issue.Notes, wiz.IssueNote{CreatedAt: now, Text: *input.Patch.Note})
+	}
+	if input.Patch.DueAt != nil {
+		issue.DueAt = input.Patch.DueAt
+	}
+	issue.UpdatedAt = now
+
+	return map[string]interface{}{"updateIssue": map[string]interface{}{"issue": *issue}}, nil
+}
+
+// customRoleIndex returns the index of a role that may be modified; built-in roles are rejected as Wiz does.
+func (s *Server) customRoleIndex(id string) (int, *Error) {
+	i := slices.IndexFunc(s.fixtures.Roles, func(r wiz.UserRole) bool { return r.ID == id })
+	if i < 0 {
+		return -1, notFound("role", id)
+	}
+	if s.fixtures.Roles[i].Builtin {
+		return -1, &Error{Message: fmt.Sprintf("role %s is built-in and cannot be modified", id), Code: "BAD_USER_INPUT"}
+	}
+	return i, nil
+}
+
+func (s *Server) userIndex(id string) int {
+	return slices.IndexFunc(s.fixtures.Users, func(u wiz.User) bool { return u.ID == id })
+}
+
+func (s *Server) issueIndex(id string) int {
+	return slices.IndexFunc(s.fixtures.Issues, func(i wiz.Issue) bool { return i.ID == id })
+}
+
+func (s *Server) reportIndex(id string) int {
+	return slices.IndexFunc(s.fixtures.Reports, func(r wiz.Report) bool { return r.ID == id })
+}
+
+func (s *Server) projectIndex(id string) int {
+	return slices.IndexFunc(s.fixtures.Projects, func(p wiz.Project) bool { return p.ID == id })
+}
+
+func (s *Server) roleRef(id string) (wiz.UserRoleRef, *Error) {
+	for _, role := range s.fixtures.Roles {
+		if role.ID == id {
+			return wiz.UserRoleRef{ID: role.ID, Name: role.Name, Scopes: role.Scopes, IsProjectScoped: role.IsProjectScoped}, nil
+		}
+	}
+	return wiz.UserRoleRef{}, notFound("role", id)
+}
+
+func (s *Server) projectRefs(ids []string) ([]wiz.ProjectRef, *Error) {
+	refs := []wiz.ProjectRef{}
+	for _, id := range ids {
+		i := s.projectIndex(id)
+		if i < 0 {
+			return nil, notFound("project", id)
+		}
+		refs = append(refs, wiz.ProjectRef{ID: id, Name: s.fixtures.Projects[i].Name, Archived: s.fixtures.Projects[i].Archived})
+	}
+	return refs, nil
+}
+
+// archiveProjectRefs updates the archived flag of every reference to a project held by users, cloud accounts and
+// SAML group mappings. Reference slices are copied rather than changed in place, as they are shared with the fixtures
+// the server was created from.
+func (s *Server) archiveProjectRefs(projectID string, archived bool) {
+	update := func(refs []wiz.ProjectRef) []wiz.ProjectRef {
+		out := slices.Clone(refs)
+		for i := range out {
+			if out[i].ID == projectID {
+				out[i].Archived = archived
+			}
+		}
+		return out
+	}
+
+	for i := range s.fixtures.Users {
+		user := &s.fixtures.Users[i]
+		user.EffectiveAssignedProjects = update(user.EffectiveAssignedProjects)
+		user.AssignedProjects = update(user.AssignedProjects)
+	}
+	for i := range s.fixtures.CloudAccounts {
+		account := &s.fixtures.CloudAccounts[i]
+		account.LinkedProjects = update(account.LinkedProjects)
+	}
+	for i := range s.fixtures.SAMLIdentityProviders {
+		idp := &s.fixtures.SAMLIdentityProviders[i]
+		mappings := slices.Clone(idp.GroupMapping)
+		for j := range mappings {
+			mappings[j].Projects = update(mappings[j].Projects)
+		}
+		idp.GroupMapping = mappings
+	}
+}
+
+// filterIssues applies the status, severity, type, related entity type, project, createdAt and updatedAt parts
+// of the IssueFilters input.
+func filterIssues(issues []wiz.Issue, variables map[string]interface{}) ([]wiz.Issue, *Error) {
+	var filterBy struct {
+		Status        []string `json:"status"`
+		Severity      []string `json:"severity"`
+		Type          []string `json:"type"`
+		Project       []string `json:"project"`
+		RelatedEntity struct {
+			Type []string `json:"type"`
+		} `json:"relatedEntity"`
+		CreatedAt struct {
+			After *time.Time `json:"after"`
+		} `json:"createdAt"`
+		UpdatedAt struct {
+			After *time.Time `json:"after"`
+		} `json:"updatedAt"`
+	}
+	if err := decodeVariable(variables, "filterBy", &filterBy); err != nil {
+		return nil, err
+	}
+
+	matches := func(allowed []string, value string) bool {
+		return len(allowed) == 0 || slices.Contains(allowed, value)
+	}
+
+	filtered := []wiz.Issue{}
+	for _, issue := range issues {
+		if !matches(filterBy.Status, issue.Status) ||
+			!matches(filterBy.Severity, issue.Severity) ||
+			!matches(filterBy.Type, issue.Type) ||
+			!matches(filterBy.RelatedEntity.Type, issue.EntitySnapshot.Type) {
+			continue
+		}
+		if len(filterBy.Project) > 0 && !slices.ContainsFunc(issue.Projects, func(p wiz.IssueProject) bool {
+			return slices.Contains(filterBy.Project, p.ID)
+		}) {
+			continue
+		}
+		if filterBy.CreatedAt.After != nil && !issue.CreatedAt.After(*filterBy.CreatedAt.After) {
+			continue
+		}
+		if filterBy.UpdatedAt.After != nil && !issue.UpdatedAt.After(*filterBy.UpdatedAt.After) {
+			continue
+		}
+		filtered = append(filtered, issue)
+	}
+	return filtered, nil
+}
+
+// filterAuditLogEntries returns the entries recorded after the filterBy timestamp, oldest first.
+func filterAuditLogEntries(entries []wiz.AuditLogEntry, variables map[string]interface{}) ([]wiz.AuditLogEntry, *Error) {
+	var filterBy struct {
+		Timestamp struct {
+			After *time.Time `json:"after"`
+		} `json:"timestamp"`
+	}
+	if err := decodeVariable(variables, "filterBy", &filterBy); err != nil {
+		return nil, err
+	}
+
+	filtered := []wiz.AuditLogEntry{}
+	for _, entry := range entries {
+		if filterBy.Timestamp.After != nil && !entry.Timestamp.After(*filterBy.Timestamp.After) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	slices.SortStableFunc(filtered, func(a, b wiz.AuditLogEntry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return filtered, nil
+}
+
+type connection[T any] struct {
+	Nodes    []T          `json:"nodes"`
+	PageInfo wiz.PageInfo `json:"pageInfo"`
+}
+
+// paginate returns the Relay page of items after the cursor held in variables[afterVar].
+// Cursors are opaque base64 strings encoding the index of the last node returned.
+func paginate[T any](items []T, variables map[string]interface{}, afterVar string, pageSize int) (*connection[T], *Error) {
+	size := pageSize
+	if first, ok := variables["first"].(float64); ok && first > 0 && int(first) < size {
+		size = int(first)
+	}
+
+	start := 0
+	if after, _ := variables[afterVar].(string); after != "" {
+		index, err := decodeCursor(after)
+		if err != nil || index < 0 || index >= len(items) {
+			return nil, &Error{Message: fmt.Sprintf("invalid cursor %q", after), Code: "BAD_USER_INPUT"}
+		}
+		start = index + 1
+	}
+	end := min(start+size, len(items))
+
+	conn := &connection[T]{Nodes: nonNil(items[start:end])}
+	if end > start {
+		conn.PageInfo.EndCursor = encodeCursor(end - 1)
+	}
+	conn.PageInfo.HasNextPage = end < len(items)
+
+	return conn, nil
+}
+
+func encodeCursor(index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(index)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	index, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return 0, fmt.Errorf("unexpected cursor %q", raw)
+	}
+	return strconv.Atoi(index)
+}
+
+func decodeVariable(variables map[string]interface{}, name string, target interface{}) *Error {
+	raw, err := json.Marshal(variables[name])
+	if err == nil {
+		err = json.Unmarshal(raw, target)
+	}
+	if err != nil {
+		return &Error{Message: fmt.Sprintf("invalid variable $%s: %v", name, err), Code: "BAD_USER_INPUT"}
+	}
+	return nil
+}
+
+func notFound(kind, id string) *Error {
+	return &Error{Message: fmt.Sprintf("%s %s not found", kind, id), Code: "NOT_FOUND"}
+}
+
+// nonNil keeps empty lists serialized as [] rather than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func graphQLErrors(errs ...Error) map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(errs))
+	for _, e := range errs {
+		entry := map[string]interface{}{"message": e.Message}
+		if len(e.Path) > 0 {
+			entry["path"] = e.Path
+		}
+		extensions := map[string]interface{}{}
+		if e.Code != "" {
+			extensions["code"] = e.Code
+		}
+		if e.RetryAfter > 0 {
+			extensions["retryAfter"] = e.RetryAfter.Seconds()
+		}
+		if len(extensions) > 0 {
+			entry["extensions"] = extensions
+		}
+		out = append(out, entry)
+	}
+	return map[string]interface{}{"data": nil, "errors": out}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}